@@ -0,0 +1,110 @@
+package http_utils
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/* An http.ResponseWriter that records the status code and body size written through it, i.e. for logging or metrics */
+type StatusRecorder struct {
+	http.ResponseWriter
+	Status int // 200 if the handler never calls WriteHeader
+	Bytes  int64
+	wrote  bool
+}
+
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w, Status: http.StatusOK}
+}
+
+func (s *StatusRecorder) WriteHeader(statusCode int) {
+	if !s.wrote {
+		s.Status = statusCode
+		s.wrote = true
+	}
+	s.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (s *StatusRecorder) Write(b []byte) (int, error) {
+	s.wrote = true
+	n, err := s.ResponseWriter.Write(b)
+	s.Bytes += int64(n)
+	return n, err
+}
+
+/* Passes Flush through when the wrapped writer supports it */
+func (s *StatusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		s.wrote = true
+		flusher.Flush()
+	}
+}
+
+/* For http.ResponseController */
+func (s *StatusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+/* Line formats for AccessLogMiddleware */
+type AccessLogFormat int
+
+const (
+	CommonLog   AccessLogFormat = iota // NCSA Common Log Format
+	CombinedLog                        // Common Log Format plus "referer" "user-agent"
+	JSONLog                            // one json object per line, including duration_ms
+)
+
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+/* Middleware writing one access log line per request to w after the handler completes, in format */
+func AccessLogMiddleware(w io.Writer, format AccessLogFormat) func(http.Handler) http.Handler {
+	var mu sync.Mutex
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := NewStatusRecorder(rw)
+			next.ServeHTTP(recorder, r)
+			line := formatAccessLog(format, r, recorder, start, time.Since(start))
+			mu.Lock()
+			io.WriteString(w, line)
+			mu.Unlock()
+		})
+	}
+}
+
+func formatAccessLog(format AccessLogFormat, r *http.Request, recorder *StatusRecorder, start time.Time, duration time.Duration) string {
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = u
+	}
+	if format == JSONLog {
+		line, _ := Marshal(map[string]interface{}{
+			"time":        start.Format(time.RFC3339),
+			"remote_addr": ClientIP(r),
+			"user":        user,
+			"method":      r.Method,
+			"uri":         r.RequestURI,
+			"proto":       r.Proto,
+			"status":      recorder.Status,
+			"bytes":       recorder.Bytes,
+			"duration_ms": float64(duration.Microseconds()) / 1000,
+			"referer":     r.Referer(),
+			"user_agent":  r.UserAgent(),
+		})
+		return string(line) + "\n"
+	}
+	bytes := "-"
+	if recorder.Bytes > 0 {
+		bytes = strconv.FormatInt(recorder.Bytes, 10)
+	}
+	line := fmt.Sprintf("%s - %s [%s] %q %d %s", ClientIP(r), user, start.Format(clfTimeFormat),
+		r.Method+" "+r.RequestURI+" "+r.Proto, recorder.Status, bytes)
+	if format == CombinedLog {
+		line += fmt.Sprintf(" %q %q", r.Referer(), r.UserAgent())
+	}
+	return line + "\n"
+}
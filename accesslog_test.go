@@ -0,0 +1,95 @@
+package http_utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+/* host ident user [time] "request" status bytes, then "referer" "user-agent" for CombinedLog */
+var clfLine = regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "([^"]*)" (\d{3}) (\S+)(?: "([^"]*)" "([^"]*)")?\n$`)
+
+func serveAccessLogged(t *testing.T, format AccessLogFormat) string {
+	t.Helper()
+	var buf bytes.Buffer
+	handler := AccessLogMiddleware(&buf, format)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("short and stout"))
+	}))
+	r := httptest.NewRequest(http.MethodPost, "/pots?size=small", nil)
+	r.SetBasicAuth("alice", "secret")
+	r.Header.Set("Referer", "https://example.com/")
+	r.Header.Set("User-Agent", "test-agent/1.0")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	return buf.String()
+}
+
+func TestAccessLogCommonAndCombined(t *testing.T) {
+	for _, format := range []AccessLogFormat{CommonLog, CombinedLog} {
+		line := serveAccessLogged(t, format)
+		m := clfLine.FindStringSubmatch(line)
+		if m == nil {
+			t.Fatalf("format %d: %q does not parse", format, line)
+		}
+		if _, err := time.Parse(clfTimeFormat, m[4]); err != nil {
+			t.Errorf("format %d: time %q: %v", format, m[4], err)
+		}
+		want := []string{"192.0.2.1", "-", "alice", "", "POST /pots?size=small HTTP/1.1", "418", "15"}
+		for i, w := range want {
+			if w != "" && m[i+1] != w {
+				t.Errorf("format %d: field %d = %q, want %q", format, i+1, m[i+1], w)
+			}
+		}
+		referer, userAgent := m[8], m[9]
+		if format == CombinedLog && (referer != "https://example.com/" || userAgent != "test-agent/1.0") {
+			t.Errorf("combined: referer %q, user agent %q", referer, userAgent)
+		}
+		if format == CommonLog && (referer != "" || userAgent != "") {
+			t.Errorf("common: unexpected referer and user agent in %q", line)
+		}
+	}
+}
+
+func TestAccessLogJSON(t *testing.T) {
+	line := serveAccessLogged(t, JSONLog)
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("%q: %v", line, err)
+	}
+	want := map[string]interface{}{
+		"remote_addr": "192.0.2.1",
+		"user":        "alice",
+		"method":      "POST",
+		"uri":         "/pots?size=small",
+		"proto":       "HTTP/1.1",
+		"status":      float64(418),
+		"bytes":       float64(15),
+		"referer":     "https://example.com/",
+		"user_agent":  "test-agent/1.0",
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %v, want %v", key, entry[key], value)
+		}
+	}
+	if _, err := time.Parse(time.RFC3339, entry["time"].(string)); err != nil {
+		t.Errorf("time: %v", err)
+	}
+	if duration, ok := entry["duration_ms"].(float64); !ok || duration < 0 {
+		t.Errorf("duration_ms = %v", entry["duration_ms"])
+	}
+}
+
+func TestAccessLogNoBodyUsesDash(t *testing.T) {
+	var buf bytes.Buffer
+	handler := AccessLogMiddleware(&buf, CommonLog)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	m := clfLine.FindStringSubmatch(buf.String())
+	if m == nil || m[3] != "-" || m[6] != "200" || m[7] != "-" {
+		t.Errorf("line %q, want user -, status 200 and bytes -", buf.String())
+	}
+}
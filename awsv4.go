@@ -0,0 +1,197 @@
+package http_utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+/* Credentials and scope for NewAWSV4Transport */
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // empty unless using temporary credentials
+	Region          string // i.e. "us-east-1"
+	Service         string // i.e. "s3", "execute-api", "lambda"
+}
+
+/*
+Signs requests with AWS Signature Version 4, for S3, API Gateway, Lambda function urls etc.
+See https://docs.aws.amazon.com/IAM/latest/UserGuide/create-signed-request.html
+*/
+type AWSV4Signer struct {
+	Now func() time.Time // nil for time.Now, set in tests for a fixed signature
+}
+
+const awsV4Algorithm = "AWS4-HMAC-SHA256"
+
+/*
+Adds the X-Amz-Date, X-Amz-Security-Token (with a sessionToken), X-Amz-Content-Sha256 (for s3) and Authorization headers to req.
+
+  - body <[]byte> : the exact request body, nil for none, it is hashed into the signature and not read from req.Body
+
+The host, content-type and x-amz-* headers are signed, so set them before signing
+*/
+func (s AWSV4Signer) Sign(req *http.Request, body []byte, service, region, accessKey, secretKey, sessionToken string) error {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	t := now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+	if service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	canonicalHeaders, signedHeaders := awsCanonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsCanonicalPath(req.URL.Path, service != "s3"),
+		awsCanonicalQuery(req),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := awsV4Algorithm + "\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", awsV4Algorithm+" Credential="+accessKey+"/"+scope+", SignedHeaders="+signedHeaders+", Signature="+signature)
+	return nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+/* AWS URI encoding, everything but A-Z a-z 0-9 - _ . ~ is percent encoded with uppercase hex */
+func awsURIEncode(s string) string {
+	var encoded strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			encoded.WriteByte(c)
+			continue
+		}
+		encoded.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+	}
+	return encoded.String()
+}
+
+/* Each path segment is encoded once for s3 and twice for every other service */
+func awsCanonicalPath(path string, twice bool) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = awsURIEncode(segment)
+		if twice {
+			segments[i] = awsURIEncode(segments[i])
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+/* Encoded params sorted by key and then value, sorting the joined key=value strings would put "a-b=" before "a=" */
+func awsCanonicalQuery(req *http.Request) string {
+	type param struct{ key, value string }
+	var params []param
+	for key, values := range req.URL.Query() {
+		for _, value := range values {
+			params = append(params, param{awsURIEncode(key), awsURIEncode(value)})
+		}
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].key != params[j].key {
+			return params[i].key < params[j].key
+		}
+		return params[i].value < params[j].value
+	})
+	pairs := make([]string, len(params))
+	for i, p := range params {
+		pairs[i] = p.key + "=" + p.value
+	}
+	return strings.Join(pairs, "&")
+}
+
+/* The canonical header block (with its trailing blank line) and the signed header list */
+func awsCanonicalHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			trimmed := make([]string, len(values))
+			for i, value := range values {
+				trimmed[i] = strings.Join(strings.Fields(value), " ")
+			}
+			headers[lower] = strings.Join(trimmed, ",")
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+	return canonical.String(), strings.Join(names, ";")
+}
+
+/* An http.RoundTripper signing every request with cfg, inner nil for http.DefaultTransport */
+func NewAWSV4Transport(inner http.RoundTripper, cfg AWSCredentials) http.RoundTripper {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		var body []byte
+		if req.Body != nil && req.Body != http.NoBody {
+			var err error
+			body, err = io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+		signed := req.Clone(req.Context())
+		if body != nil {
+			signed.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		if err := (AWSV4Signer{}).Sign(signed, body, cfg.Service, cfg.Region, cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken); err != nil {
+			return nil, err
+		}
+		return inner.RoundTrip(signed)
+	})
+}
@@ -0,0 +1,34 @@
+package http_utils
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+/* Standard base64 without = padding */
+func Base64Encode(data []byte) string {
+	return base64.RawStdEncoding.EncodeToString(data)
+}
+
+/* URL safe base64 without = padding, as in JWT segments */
+func Base64URLEncode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+/* Decodes standard base64, with or without = padding. Padding, when present, must be complete and no longer than needed */
+func Base64Decode(s string) ([]byte, error) {
+	return decodeBase64(s, base64.StdEncoding, base64.RawStdEncoding)
+}
+
+/* Decodes URL safe base64, with or without = padding. Padding, when present, must be complete and no longer than needed */
+func Base64URLDecode(s string) ([]byte, error) {
+	return decodeBase64(s, base64.URLEncoding, base64.RawURLEncoding)
+}
+
+/* Padded input goes to padded, which checks the padding, rather than trimming any number of = off for raw */
+func decodeBase64(s string, padded, raw *base64.Encoding) ([]byte, error) {
+	if strings.HasSuffix(s, "=") {
+		return padded.DecodeString(s)
+	}
+	return raw.DecodeString(s)
+}
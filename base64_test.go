@@ -0,0 +1,64 @@
+package http_utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBase64RoundTrip(t *testing.T) {
+	for _, data := range [][]byte{{}, []byte("A"), []byte("AB"), []byte("ABC"), {0xfb, 0xff, 0xbf}} {
+		if got, err := Base64Decode(Base64Encode(data)); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("std %q: got %q, %v", data, got, err)
+		}
+		if got, err := Base64URLDecode(Base64URLEncode(data)); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("url %q: got %q, %v", data, got, err)
+		}
+	}
+}
+
+func TestBase64DecodeEmpty(t *testing.T) {
+	for name, decode := range map[string]func(string) ([]byte, error){"std": Base64Decode, "url": Base64URLDecode} {
+		if got, err := decode(""); err != nil || len(got) != 0 {
+			t.Errorf("%s: got %q, %v", name, got, err)
+		}
+	}
+}
+
+func TestBase64DecodePadding(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"QQ", "A"},
+		{"QQ==", "A"},
+		{"QUI", "AB"},
+		{"QUI=", "AB"},
+		{"QUJD", "ABC"},
+	}
+	for _, tt := range tests {
+		got, err := Base64Decode(tt.in)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("Base64Decode(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"QQ=", "QQ===", "QQ=====", "QUI==", "QUJD=", "="} {
+		if got, err := Base64Decode(in); err == nil {
+			t.Errorf("Base64Decode(%q) = %q, expected a padding error", in, got)
+		}
+	}
+}
+
+func TestBase64DecodeInvalidCharacters(t *testing.T) {
+	if _, err := Base64Decode("QQ!!"); err == nil {
+		t.Error("std: ! accepted")
+	}
+	if _, err := Base64Decode("-_8"); err == nil {
+		t.Error("std: url alphabet accepted")
+	}
+	if _, err := Base64URLDecode("+/8"); err == nil {
+		t.Error("url: std alphabet accepted")
+	}
+	if got, err := Base64URLDecode("-_8"); err != nil || !bytes.Equal(got, []byte{0xfb, 0xff}) {
+		t.Errorf("url: got %x, %v", got, err)
+	}
+}
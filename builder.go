@@ -0,0 +1,99 @@
+package http_utils
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+)
+
+/*
+Fluent builder over HttpPostReqContext, i.e.
+
+	resp, err := NewRequestBuilder(MethodPost, url).JSON(order).Bearer(token).Timeout(5 * time.Second).Do(ctx)
+
+Headers are added on top of the HttpPostReq defaults
+*/
+type RequestBuilder struct {
+	method  string
+	url     string
+	payload interface{}
+	headers []ReqHeader
+	timeout time.Duration
+	config  *ClientConfig
+}
+
+func NewRequestBuilder(method, url string) *RequestBuilder {
+	return &RequestBuilder{method: method, url: url}
+}
+
+/* Sets the payload, encoded by the ClientConfig Serialiser, json by default */
+func (b *RequestBuilder) JSON(payload interface{}) *RequestBuilder {
+	b.payload = payload
+	return b
+}
+
+func (b *RequestBuilder) Header(name, value string) *RequestBuilder {
+	b.headers = append(b.headers, ReqHeader{HeaderName: name, HeaderValue: value})
+	return b
+}
+
+/* Sets "Authorization: Basic <base64 user:pass>" */
+func (b *RequestBuilder) BasicAuth(user, pass string) *RequestBuilder {
+	return b.Header("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user+":"+pass)))
+}
+
+/* Sets "Authorization: Bearer <token>" */
+func (b *RequestBuilder) Bearer(token string) *RequestBuilder {
+	return b.Header("Authorization", "Bearer "+token)
+}
+
+/* Limits the whole request, including reading the body, to d */
+func (b *RequestBuilder) Timeout(d time.Duration) *RequestBuilder {
+	b.timeout = d
+	return b
+}
+
+/* Sends the request with cfg rather than the defaults */
+func (b *RequestBuilder) Config(cfg *ClientConfig) *RequestBuilder {
+	b.config = cfg
+	return b
+}
+
+/* Option for a single RequestBuilder.Do call */
+type DoOption func(*doOptions)
+
+type doOptions struct {
+	retry *RetryConfig
+}
+
+/*
+Retries this call only, built from opts on top of a single attempt, so a shared RequestBuilder or ClientConfig is unaffected, i.e.
+
+	resp, err := builder.Do(ctx, WithRetry(RetryMaxAttempts(5), RetryBackoff(ExponentialBackoff(100*time.Millisecond, 5*time.Second, 2))))
+*/
+func WithRetry(opts ...RetryOption) DoOption {
+	return func(o *doOptions) {
+		retry := RetryConfig{MaxAttempts: 1}
+		for _, opt := range opts {
+			opt(&retry)
+		}
+		o.retry = &retry
+	}
+}
+
+/* Sends the request, the Timeout covers all attempts when retrying */
+func (b *RequestBuilder) Do(ctx context.Context, opts ...DoOption) (*Response, error) {
+	var o doOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+	if o.retry != nil {
+		return HttpPostReqWithRetry(ctx, *o.retry, b.config, b.method, b.payload, b.url, nil, b.headers)
+	}
+	return HttpPostReqContext(ctx, b.config, b.method, b.payload, b.url, nil, b.headers)
+}
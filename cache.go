@@ -0,0 +1,167 @@
+package http_utils
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+An in memory GET cache in front of an *http.Client, for read heavy services calling the same urls repeatedly.
+Only 200 responses are cached, for their Cache-Control max-age if set, otherwise defaultTTL.
+Responses marked no-store or no-cache are not cached
+*/
+type CachedClient struct {
+	inner      *http.Client
+	defaultTTL time.Duration
+	entries    sync.Map // CanonicalKey => cacheEntry
+}
+
+type cacheEntry struct {
+	url      string // canonical, for Invalidate
+	response *Response
+	expires  time.Time
+}
+
+/* inner nil for http.DefaultClient */
+func NewCachedClient(inner *http.Client, defaultTTL time.Duration) *CachedClient {
+	if inner == nil {
+		inner = http.DefaultClient
+	}
+	return &CachedClient{inner: inner, defaultTTL: defaultTTL}
+}
+
+/*
+GETs url, from the cache when there is a fresh entry. The bool reports a cache hit.
+Entries are keyed by CanonicalKey, so query param order does not matter and different Authorization headers get separate entries.
+Cached responses are shared between callers, treat them as read only
+*/
+func (c *CachedClient) Get(ctx context.Context, url string, headers []ReqHeader) (*Response, bool, error) {
+	key, err := CanonicalKey(http.MethodGet, url, headers)
+	if err != nil {
+		return nil, false, err
+	}
+	if value, ok := c.entries.Load(key); ok {
+		entry := value.(cacheEntry)
+		if time.Now().Before(entry.expires) {
+			return entry.response, true, nil
+		}
+		c.entries.CompareAndDelete(key, value)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	request.Header = ToHTTPHeader(headers)
+	response, err := c.inner.Do(request)
+	if err != nil {
+		return nil, false, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	result := &Response{
+		StatusCode: StatusCode(response.StatusCode),
+		Status:     response.Status,
+		Header:     response.Header,
+		Body:       body,
+	}
+	if ttl, ok := c.ttl(response); ok && response.StatusCode == http.StatusOK {
+		canonical, _ := canonicalURL(url) // parsed fine in CanonicalKey
+		c.entries.Store(key, cacheEntry{url: canonical, response: result, expires: time.Now().Add(ttl)})
+	}
+	return result, false, nil
+}
+
+/* The cache lifetime of a response from its Cache-Control header, false if it must not be cached */
+func (c *CachedClient) ttl(response *http.Response) (time.Duration, bool) {
+	ttl := c.defaultTTL
+	for _, directive := range strings.Split(response.Header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return 0, false
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+				ttl = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return ttl, ttl > 0
+}
+
+/* Drops the cached responses for url, whatever headers they were fetched with */
+func (c *CachedClient) Invalidate(url string) {
+	canonical, err := canonicalURL(url)
+	if err != nil {
+		return
+	}
+	c.entries.Range(func(key, value interface{}) bool {
+		if value.(cacheEntry).url == canonical {
+			c.entries.Delete(key)
+		}
+		return true
+	})
+}
+
+/* Drops every cached response */
+func (c *CachedClient) Flush() {
+	c.entries.Range(func(key, _ interface{}) bool {
+		c.entries.Delete(key)
+		return true
+	})
+}
+
+/* Headers left out of CanonicalKey, they vary per request without changing the response */
+var canonicalKeyIgnoredHeaders = map[string]bool{
+	"User-Agent":   true,
+	"X-Request-Id": true,
+	"Traceparent":  true,
+	"Tracestate":   true,
+}
+
+/*
+A SHA-256 hex key identifying a request, for caching and deduplication. Requests differing only in query param order,
+scheme or host case, or a fragment get the same key.
+
+  - headers <[]ReqHeader> : every header counts (names case insensitive, in any order) except User-Agent, X-Request-ID,
+    traceparent and tracestate, so i.e. responses for different Authorization tokens never share a key
+*/
+func CanonicalKey(method, url string, headers []ReqHeader) (string, error) {
+	canonical, err := canonicalURL(url)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, header := range headers {
+		name := http.CanonicalHeaderKey(header.HeaderName)
+		if !canonicalKeyIgnoredHeaders[name] {
+			lines = append(lines, name+":"+strings.TrimSpace(header.HeaderValue))
+		}
+	}
+	sort.Strings(lines)
+	return sha256Hex([]byte(strings.ToUpper(method) + "\n" + canonical + "\n" + strings.Join(lines, "\n"))), nil
+}
+
+/* url with a lowercase scheme and host, sorted query params and no fragment */
+func canonicalURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	parsed.RawQuery = parsed.Query().Encode()
+	return parsed.String(), nil
+}
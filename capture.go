@@ -0,0 +1,102 @@
+package http_utils
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+)
+
+/* One request/response pair recorded by BodyCaptureTransport, Response is nil and Err set if the round trip failed */
+type CapturedEntry struct {
+	Request      *http.Request
+	RequestBody  []byte
+	Response     *http.Response
+	ResponseBody []byte
+	Err          error
+}
+
+/*
+An http.RoundTripper that keeps the last capacity request/response pairs, with their bodies, for inspecting recent traffic.
+Response bodies are read fully and replaced with in memory copies, so avoid it for streaming responses
+*/
+type BodyCaptureTransport struct {
+	inner    http.RoundTripper
+	mu       sync.Mutex
+	entries  []CapturedEntry // ring buffer
+	next     int
+	full     bool
+	capacity int
+}
+
+/* inner nil for http.DefaultTransport, capacity less than 1 is treated as 1 */
+func NewBodyCaptureTransport(inner http.RoundTripper, capacity int) *BodyCaptureTransport {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &BodyCaptureTransport{inner: inner, entries: make([]CapturedEntry, capacity), capacity: capacity}
+}
+
+func (t *BodyCaptureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := CapturedEntry{Request: req}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		entry.RequestBody = body
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		entry.Request = req
+	}
+	resp, err := t.inner.RoundTrip(req)
+	if err != nil {
+		entry.Err = err
+		t.add(entry)
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	entry.Response = resp
+	entry.ResponseBody = body
+	entry.Err = err
+	t.add(entry)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *BodyCaptureTransport) add(entry CapturedEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries[t.next] = entry
+	t.next = (t.next + 1) % t.capacity
+	if t.next == 0 {
+		t.full = true
+	}
+}
+
+/* The captured entries, oldest first */
+func (t *BodyCaptureTransport) Entries() []CapturedEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.full {
+		return append([]CapturedEntry(nil), t.entries[:t.next]...)
+	}
+	return append(append([]CapturedEntry(nil), t.entries[t.next:]...), t.entries[:t.next]...)
+}
+
+/* Drops all captured entries */
+func (t *BodyCaptureTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = make([]CapturedEntry, t.capacity)
+	t.next = 0
+	t.full = false
+}
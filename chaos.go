@@ -0,0 +1,73 @@
+package http_utils
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+/*
+An http.RoundTripper waiting delay before every request, to simulate a slow upstream in tests. inner nil for http.DefaultTransport.
+A request whose context is done during the wait fails with the context's error without being sent
+*/
+func NewDelayTransport(inner http.RoundTripper, delay time.Duration) http.RoundTripper {
+	return NewJitterTransport(inner, delay, delay)
+}
+
+/* NewDelayTransport with a uniformly random delay between minDelay and maxDelay for each request */
+func NewJitterTransport(inner http.RoundTripper, minDelay, maxDelay time.Duration) http.RoundTripper {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if err := SleepWithContext(req.Context(), randomDuration(minDelay, maxDelay)); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
+		return inner.RoundTrip(req)
+	})
+}
+
+/* Returned by a NewErrorTransport given a nil err */
+var ErrInjectedFailure = errors.New("http_utils: injected request failure")
+
+/*
+An http.RoundTripper failing about errorRate of requests with err instead of sending them, to test error handling and retries
+
+  - errorRate <float64> : share of requests to fail, below 0 (or NaN) is treated as 0 and above 1 as 1
+
+  - err <error> : the error returned, nil for ErrInjectedFailure, as a nil error with no response would break net/http
+
+inner nil for http.DefaultTransport. Requests whose context is already done fail with the context's error
+*/
+func NewErrorTransport(inner http.RoundTripper, errorRate float64, err error) http.RoundTripper {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	if !(errorRate > 0) {
+		errorRate = 0
+	} else if errorRate > 1 {
+		errorRate = 1
+	}
+	if err == nil {
+		err = ErrInjectedFailure
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			closeRequestBody(req)
+			return nil, ctxErr
+		}
+		if errorRate == 1 || (errorRate > 0 && randomFloat64() < errorRate) {
+			closeRequestBody(req)
+			return nil, err
+		}
+		return inner.RoundTrip(req)
+	})
+}
+
+/* A RoundTripper failing before sending must still close the request body */
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -0,0 +1,96 @@
+package http_utils
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+var okTransport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+})
+
+/* How many of n requests through rt fail */
+func countFailures(t *testing.T, rt http.RoundTripper, n int) int {
+	t.Helper()
+	failures := 0
+	for i := 0; i < n; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://chaos.invalid/", nil)
+		if _, err := rt.RoundTrip(req); err != nil {
+			failures++
+		}
+	}
+	return failures
+}
+
+func TestErrorTransportRates(t *testing.T) {
+	tests := []struct {
+		rate     float64
+		min, max int
+	}{
+		{0, 0, 0},
+		{-1, 0, 0},
+		{math.NaN(), 0, 0},
+		{1, 1000, 1000},
+		{2, 1000, 1000},
+		{0.5, 350, 650},
+	}
+	for _, tt := range tests {
+		got := countFailures(t, NewErrorTransport(okTransport, tt.rate, errors.New("injected")), 1000)
+		if got < tt.min || got > tt.max {
+			t.Errorf("errorRate %v: %d of 1000 failed, want %d to %d", tt.rate, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestErrorTransportNilErr(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://chaos.invalid/", nil)
+	response, err := NewErrorTransport(okTransport, 1, nil).RoundTrip(req)
+	if response != nil || !errors.Is(err, ErrInjectedFailure) {
+		t.Errorf("got %v, %v, want ErrInjectedFailure", response, err)
+	}
+}
+
+func TestErrorTransportCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://chaos.invalid/", nil)
+	if _, err := NewErrorTransport(okTransport, 0, nil).RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestDelayTransport(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://chaos.invalid/", nil)
+	start := time.Now()
+	if _, err := NewDelayTransport(okTransport, 20*time.Millisecond).RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("returned after %s, want at least 20ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, "http://chaos.invalid/", nil)
+	if _, err := NewDelayTransport(okTransport, time.Second).RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the delay cut short by the deadline", err)
+	}
+}
+
+func TestErrorTransportClosesBodyOnCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	body := &closeTrackingBody{Reader: strings.NewReader("payload")}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "http://chaos.invalid/", body)
+	if _, err := NewErrorTransport(okTransport, 0, nil).RoundTrip(req); err == nil {
+		t.Fatal("expected the context error")
+	}
+	if !body.closed {
+		t.Error("request body was not closed")
+	}
+}
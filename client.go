@@ -0,0 +1,269 @@
+package http_utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+/* Signature of net.Dialer.DialContext and http.Transport.DialContext */
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+/*
+Optional configuration for HttpPostReqWithConfig, a nil *ClientConfig or zero value fields fall back to the defaults.
+Share one *ClientConfig between requests: its *http.Client (and so its connection pool) is built on the first request
+and reused after, which also means changing its fields after the first request has no effect
+
+  - Serialiser <Serialiser> : encodes the payload, defaults to JSONSerialiser
+
+  - DialContext <DialContextFunc> : custom dialer for the transport i.e. StaticResolver, defaults to the system resolver
+
+  - MaxRedirects <*int> : redirects to follow before failing with a *MaxRedirectError, nil for the net/http default of 10,
+    0 to not follow redirects and return the redirect response itself
+
+  - Timeout <time.Duration> : limit for the whole request including reading the body, 0 for no limit
+
+  - HostTimeouts <map[string]time.Duration> : per host limits keyed by "host:port" or "host", i.e. a short limit for
+    internal services and a longer one for slow external APIs, hosts not in the map only get Timeout
+
+  - MaxResponseBytes <int64> : largest response body to read before failing with a *ResponseTooLargeError, 0 for no limit
+
+  - Transport <http.RoundTripper> : base transport, i.e. an httptestutil.TestRoundTripper in unit tests, defaults to http.DefaultTransport.
+    DialContext and the transport timeouts below only apply when this is nil or an *http.Transport
+
+  - DialTimeout <time.Duration> : limit for establishing the TCP connection, also applied around a custom DialContext
+
+  - TLSHandshakeTimeout <time.Duration> : limit for the TLS handshake, 0 keeps the base transport's (10s for http.DefaultTransport)
+
+  - ResponseHeaderTimeout <time.Duration> : limit from writing the request to reading the response headers, the body is not included
+
+  - ExpectContinueTimeout <time.Duration> : wait for a 100 Continue before sending the body of requests with Expect: 100-continue
+*/
+type ClientConfig struct {
+	Serialiser   Serialiser
+	DialContext  DialContextFunc
+	MaxRedirects *int
+	Timeout      time.Duration
+	HostTimeouts map[string]time.Duration
+
+	MaxResponseBytes int64
+
+	Transport http.RoundTripper
+
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	ExpectContinueTimeout time.Duration
+
+	clientOnce sync.Once
+	client     *http.Client
+}
+
+/* Client for requests without a *ClientConfig, shared so they share http.DefaultTransport's pool as before */
+var defaultHTTPClient = &http.Client{}
+
+/* The client for requests made with c, built by NewConfiguredClient once and reused */
+func (c *ClientConfig) httpClient() *http.Client {
+	if c == nil {
+		return defaultHTTPClient
+	}
+	c.clientOnce.Do(func() {
+		c.client = NewConfiguredClient(c)
+	})
+	return c.client
+}
+
+func (c *ClientConfig) serialiser() Serialiser {
+	if c == nil || c.Serialiser == nil {
+		return JSONSerialiser{}
+	}
+	return c.Serialiser
+}
+
+func (c *ClientConfig) maxResponseBytes() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.MaxResponseBytes
+}
+
+/*
+Builds a new *http.Client from a *ClientConfig, nil gives the same client as &http.Client{}.
+With a DialContext or transport timeout set the client gets its own transport and connection pool,
+so build it once and keep it rather than calling this per request
+*/
+func NewConfiguredClient(cfg *ClientConfig) *http.Client {
+	client := &http.Client{}
+	if cfg == nil {
+		return client
+	}
+	client.Timeout = cfg.Timeout
+	transport := cfg.Transport
+	if cfg.customisesTransport() {
+		base, ok := transport.(*http.Transport)
+		if transport == nil {
+			base, ok = http.DefaultTransport.(*http.Transport), true
+		}
+		if ok {
+			transport = cfg.applyTransportSettings(base.Clone())
+		}
+	}
+	if len(cfg.HostTimeouts) > 0 {
+		transport = &hostTimeoutTransport{inner: transport, timeouts: cfg.HostTimeouts}
+	}
+	client.Transport = transport
+	if cfg.MaxRedirects != nil {
+		client.CheckRedirect = checkRedirect(*cfg.MaxRedirects)
+	}
+	return client
+}
+
+func (c *ClientConfig) customisesTransport() bool {
+	return c.DialContext != nil || c.DialTimeout > 0 || c.TLSHandshakeTimeout > 0 ||
+		c.ResponseHeaderTimeout > 0 || c.ExpectContinueTimeout > 0
+}
+
+/* Sets the DialContext and timeout fields that are set on t, a clone owned by the client */
+func (c *ClientConfig) applyTransportSettings(t *http.Transport) *http.Transport {
+	dial := c.DialContext
+	if c.DialTimeout > 0 {
+		if dial == nil {
+			dial = (&net.Dialer{Timeout: c.DialTimeout, KeepAlive: 30 * time.Second}).DialContext
+		} else {
+			custom, timeout := dial, c.DialTimeout
+			dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+				return custom(ctx, network, addr)
+			}
+		}
+	}
+	if dial != nil {
+		t.DialContext = dial
+	}
+	if c.TLSHandshakeTimeout > 0 {
+		t.TLSHandshakeTimeout = c.TLSHandshakeTimeout
+	}
+	if c.ResponseHeaderTimeout > 0 {
+		t.ResponseHeaderTimeout = c.ResponseHeaderTimeout
+	}
+	if c.ExpectContinueTimeout > 0 {
+		t.ExpectContinueTimeout = c.ExpectContinueTimeout
+	}
+	return t
+}
+
+/*
+Returns a func building the client with factory on its first call and returning that same client on every call after,
+safe to call from many goroutines at once i.e.
+
+	var apiClient = LazyClient(func() *http.Client { return NewConfiguredClient(cfg) })
+	...
+	apiClient().Do(req)
+*/
+func LazyClient(factory func() *http.Client) func() *http.Client {
+	var once sync.Once
+	var client *http.Client
+	return func() *http.Client {
+		once.Do(func() { client = factory() })
+		return client
+	}
+}
+
+/* Returned (wrapped in a *url.Error) when a request is redirected more than ClientConfig.MaxRedirects times */
+type MaxRedirectError struct {
+	MaxRedirects int
+	URL          string
+}
+
+func (e *MaxRedirectError) Error() string {
+	return fmt.Sprintf("stopped after %d redirects at %s", e.MaxRedirects, e.URL)
+}
+
+func checkRedirect(maxRedirects int) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if maxRedirects <= 0 {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > maxRedirects {
+			return &MaxRedirectError{MaxRedirects: maxRedirects, URL: req.URL.String()}
+		}
+		return nil
+	}
+}
+
+/*
+A DialContextFunc that maps hostnames to IP addresses without touching /etc/hosts, i.e. for
+docker compose service names or split-horizon DNS
+
+  - hosts <map[string]string> : hostname => IP address i.e. {"api.internal": "10.0.0.12"}, hosts not in the map are dialed as is
+*/
+func StaticResolver(hosts map[string]string) DialContextFunc {
+	dialer := &net.Dialer{}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if ip, ok := hosts[host]; ok {
+			addr = net.JoinHostPort(ip, port)
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}
+}
+
+/* Returned when a response body is larger than ClientConfig.MaxResponseBytes, Body holds the first Limit bytes */
+type ResponseTooLargeError struct {
+	URL   string
+	Limit int64
+	Body  []byte
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response from %s exceeds %d bytes", e.URL, e.Limit)
+}
+
+/* Applies a per host timeout to the request context before delegating to inner */
+type hostTimeoutTransport struct {
+	inner    http.RoundTripper // nil for http.DefaultTransport
+	timeouts map[string]time.Duration
+}
+
+func (t *hostTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	inner := t.inner
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	timeout, ok := t.timeouts[req.URL.Host]
+	if !ok {
+		timeout, ok = t.timeouts[req.URL.Hostname()]
+	}
+	if !ok {
+		return inner.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := inner.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// the timeout covers reading the body too, so only cancel once the caller is done with it
+	resp.Body = &closeHook{ReadCloser: resp.Body, onClose: cancel}
+	return resp, nil
+}
+
+/* Calls onClose after closing the wrapped body */
+type closeHook struct {
+	io.ReadCloser
+	onClose func()
+}
+
+func (c *closeHook) Close() error {
+	err := c.ReadCloser.Close()
+	c.onClose()
+	return err
+}
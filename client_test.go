@@ -0,0 +1,267 @@
+package http_utils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStaticResolverMapsHost(t *testing.T) {
+	var gotHost string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.Host
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	cfg := &ClientConfig{DialContext: StaticResolver(map[string]string{"fake-service.invalid": "127.0.0.1"})}
+	response, err := HttpPostReqContext(context.Background(), cfg, http.MethodGet, nil, "http://fake-service.invalid:"+port+"/", nil, nil)
+	if err != nil {
+		t.Fatalf("request through fake mapping: %v", err)
+	}
+	if string(response.Body) != `{"ok":true}` {
+		t.Errorf("body = %s", response.Body)
+	}
+	if want := "fake-service.invalid:" + port; gotHost != want {
+		t.Errorf("Host = %q, want %q", gotHost, want)
+	}
+}
+
+func TestConfiguredClientReusesConnections(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	cfg := &ClientConfig{DialContext: StaticResolver(nil)}
+	for i := 0; i < 3; i++ {
+		if _, err := HttpPostReqContext(context.Background(), cfg, http.MethodGet, nil, server.URL, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cfg.httpClient() != cfg.httpClient() {
+		t.Error("httpClient built more than once")
+	}
+	if n := conns.Load(); n != 1 {
+		t.Errorf("opened %d connections for 3 sequential requests, want 1", n)
+	}
+}
+
+/* A server redirecting /0 => /1 => ... => /hops, which responds 200 */
+func redirectChain(hops int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Path[1:])
+		if n < hops {
+			http.Redirect(w, r, "/"+strconv.Itoa(n+1), http.StatusFound)
+			return
+		}
+		w.Write([]byte("done"))
+	}))
+}
+
+func TestMaxRedirects(t *testing.T) {
+	server := redirectChain(3)
+	defer server.Close()
+
+	for _, max := range []int{3, 4} {
+		response, err := HttpPostReqContext(context.Background(), &ClientConfig{MaxRedirects: Ptr(max)}, http.MethodGet, nil, server.URL+"/0", nil, nil)
+		if err != nil {
+			t.Fatalf("MaxRedirects %d: %v", max, err)
+		}
+		if string(response.Body) != "done" {
+			t.Errorf("MaxRedirects %d: body %q", max, response.Body)
+		}
+	}
+
+	_, err := HttpPostReqContext(context.Background(), &ClientConfig{MaxRedirects: Ptr(2)}, http.MethodGet, nil, server.URL+"/0", nil, nil)
+	var redirectErr *MaxRedirectError
+	if !errors.As(err, &redirectErr) || redirectErr.MaxRedirects != 2 {
+		t.Fatalf("MaxRedirects 2: got %v, want a *MaxRedirectError", err)
+	}
+}
+
+func TestMaxRedirectsZeroReturnsRedirect(t *testing.T) {
+	server := redirectChain(3)
+	defer server.Close()
+
+	response, err := HttpPostReqContext(context.Background(), &ClientConfig{MaxRedirects: Ptr(0)}, http.MethodGet, nil, server.URL+"/0", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusFound {
+		t.Errorf("status %d, want 302", response.StatusCode)
+	}
+}
+
+/* Long enough that only the timeout under test can fire */
+const otherStageTimeout = 5 * time.Second
+
+func TestDialTimeout(t *testing.T) {
+	cfg := &ClientConfig{
+		DialTimeout: 50 * time.Millisecond,
+		// never connects, only returns when the dial's context ends
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		TLSHandshakeTimeout:   otherStageTimeout,
+		ResponseHeaderTimeout: otherStageTimeout,
+	}
+	start := time.Now()
+	_, err := cfg.httpClient().Get("http://dial-timeout.invalid/")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the dial deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("failed after %s, want about 50ms", elapsed)
+	}
+}
+
+/* A TCP listener accepting connections and never writing to them, closed with the test */
+func silentListener(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var conns []net.Conn
+	t.Cleanup(func() {
+		listener.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+	return listener
+}
+
+func TestTLSHandshakeTimeout(t *testing.T) {
+	listener := silentListener(t)
+	cfg := &ClientConfig{
+		DialTimeout:           otherStageTimeout,
+		TLSHandshakeTimeout:   50 * time.Millisecond,
+		ResponseHeaderTimeout: otherStageTimeout,
+	}
+	start := time.Now()
+	_, err := cfg.httpClient().Get("https://" + listener.Addr().String() + "/")
+	if err == nil || !strings.Contains(err.Error(), "TLS handshake timeout") {
+		t.Errorf("err = %v, want a TLS handshake timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("failed after %s, want about 50ms", elapsed)
+	}
+}
+
+func TestResponseHeaderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow-headers" {
+			time.Sleep(300 * time.Millisecond)
+			return
+		}
+		// headers straight away, then a slow body, which ResponseHeaderTimeout does not cover
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte("slow body"))
+	}))
+	defer server.Close()
+	cfg := &ClientConfig{
+		DialTimeout:           otherStageTimeout,
+		TLSHandshakeTimeout:   otherStageTimeout,
+		ResponseHeaderTimeout: 50 * time.Millisecond,
+	}
+
+	_, err := cfg.httpClient().Get(server.URL + "/slow-headers")
+	if err == nil || !strings.Contains(err.Error(), "timeout awaiting response headers") {
+		t.Errorf("slow headers: err = %v, want a response header timeout", err)
+	}
+
+	response, err := cfg.httpClient().Get(server.URL + "/slow-body")
+	if err != nil {
+		t.Fatalf("slow body: %v", err)
+	}
+	defer response.Body.Close()
+	if body, err := io.ReadAll(response.Body); err != nil || string(body) != "slow body" {
+		t.Errorf("slow body: got %q, %v", body, err)
+	}
+}
+
+func TestExpectContinueTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	// reads the request headers and never sends 100 Continue, timing how long the body takes to follow
+	bodyAfter := make(chan time.Duration, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if line == "\r\n" {
+				break
+			}
+		}
+		headersAt := time.Now()
+		if _, err := reader.ReadByte(); err != nil {
+			return
+		}
+		bodyAfter <- time.Since(headersAt)
+		io.WriteString(conn, "HTTP/1.1 200 OK\r\nContent-Length: 0\r\nConnection: close\r\n\r\n")
+	}()
+
+	cfg := &ClientConfig{
+		DialTimeout:           otherStageTimeout,
+		ResponseHeaderTimeout: otherStageTimeout,
+		ExpectContinueTimeout: 50 * time.Millisecond,
+	}
+	req, _ := http.NewRequest(http.MethodPost, "http://"+listener.Addr().String()+"/", strings.NewReader(`{"a":1}`))
+	req.Header.Set("Expect", "100-continue")
+	response, err := cfg.httpClient().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	// http.DefaultTransport waits 1s
+	if wait := <-bodyAfter; wait < 40*time.Millisecond || wait > 500*time.Millisecond {
+		t.Errorf("body sent %s after the headers, want about 50ms", wait)
+	}
+}
@@ -0,0 +1,184 @@
+package http_utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+/*
+Converts a value decoded into interface{} (a map[string]interface{} field, JSONPath result etc) to a T, i.e.
+
+	count, err := Coerce[int](claims["count"]) // float64(3) or json.Number("3") => 3
+
+The conversions are
+
+  - nil : the zero T
+
+  - to ints and uints : from float64 without a fraction, json.Number, numeric strings and other number types, erroring on overflow
+
+  - to floats : from float64, json.Number, numeric strings and other number types
+
+  - to bool : from bool and strconv.ParseBool strings ("true", "1", "false" ...)
+
+  - to string : from string, json.Number, numbers and bool
+
+Anything else (structs, slices, maps) is converted by round tripping through json
+*/
+func Coerce[T any](v interface{}) (T, error) {
+	var result T
+	if v == nil {
+		return result, nil
+	}
+	if typed, ok := v.(T); ok {
+		return typed, nil
+	}
+	target := reflect.ValueOf(&result).Elem()
+	if err := coerceInto(target, v); err != nil {
+		return result, fmt.Errorf("cannot coerce %T %v to %s: %w", v, v, target.Type(), err)
+	}
+	return result, nil
+}
+
+func coerceInto(target reflect.Value, v interface{}) error {
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := coerceInt(v)
+		if err != nil {
+			return err
+		}
+		if target.OverflowInt(n) {
+			return fmt.Errorf("%d overflows", n)
+		}
+		target.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := coerceUint(v)
+		if err != nil {
+			return err
+		}
+		if target.OverflowUint(n) {
+			return fmt.Errorf("%d overflows", n)
+		}
+		target.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := coerceFloat(v)
+		if err != nil {
+			return err
+		}
+		target.SetFloat(f)
+	case reflect.Bool:
+		switch b := v.(type) {
+		case bool:
+			target.SetBool(b)
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			if err != nil {
+				return err
+			}
+			target.SetBool(parsed)
+		default:
+			return fmt.Errorf("not a bool")
+		}
+	case reflect.String:
+		switch s := v.(type) {
+		case string:
+			target.SetString(s)
+		case json.Number:
+			target.SetString(s.String())
+		case bool:
+			target.SetString(strconv.FormatBool(s))
+		case float64:
+			target.SetString(strconv.FormatFloat(s, 'f', -1, 64))
+		default:
+			rv := reflect.ValueOf(v)
+			if !rv.CanInt() && !rv.CanUint() && !rv.CanFloat() {
+				return fmt.Errorf("not a string")
+			}
+			target.SetString(fmt.Sprint(v))
+		}
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return Unmarshal(data, target.Addr().Interface())
+	}
+	return nil
+}
+
+func coerceInt(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case float64:
+		if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+			return 0, fmt.Errorf("not a whole number in range")
+		}
+		return int64(n), nil
+	case json.Number:
+		return n.Int64()
+	case string:
+		return strconv.ParseInt(n, 10, 64)
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return rv.Int(), nil
+	case rv.CanUint():
+		if rv.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("%d overflows", rv.Uint())
+		}
+		return int64(rv.Uint()), nil
+	case rv.CanFloat():
+		return coerceInt(rv.Float())
+	}
+	return 0, fmt.Errorf("not a number")
+}
+
+/* coerceInt for uint targets, parsed as unsigned so values above math.MaxInt64 are kept */
+func coerceUint(v interface{}) (uint64, error) {
+	switch n := v.(type) {
+	case float64:
+		// float64(math.MaxUint64) rounds up to 2^64, itself out of range
+		if n != math.Trunc(n) || n < 0 || n >= math.MaxUint64 {
+			return 0, fmt.Errorf("not a whole number in range")
+		}
+		return uint64(n), nil
+	case json.Number:
+		return strconv.ParseUint(n.String(), 10, 64)
+	case string:
+		return strconv.ParseUint(n, 10, 64)
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanUint():
+		return rv.Uint(), nil
+	case rv.CanInt():
+		if rv.Int() < 0 {
+			return 0, fmt.Errorf("%d is negative", rv.Int())
+		}
+		return uint64(rv.Int()), nil
+	case rv.CanFloat():
+		return coerceUint(rv.Float())
+	}
+	return 0, fmt.Errorf("not a number")
+}
+
+func coerceFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case json.Number:
+		return n.Float64()
+	case string:
+		return strconv.ParseFloat(n, 64)
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanFloat():
+		return rv.Float(), nil
+	case rv.CanInt():
+		return float64(rv.Int()), nil
+	case rv.CanUint():
+		return float64(rv.Uint()), nil
+	}
+	return 0, fmt.Errorf("not a number")
+}
@@ -0,0 +1,82 @@
+package http_utils
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCoerceInts(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want int
+	}{
+		{float64(3), 3},
+		{json.Number("-42"), -42},
+		{"7", 7},
+		{int32(5), 5},
+		{uint8(9), 9},
+		{nil, 0},
+	}
+	for _, tt := range tests {
+		got, err := Coerce[int](tt.v)
+		if err != nil || got != tt.want {
+			t.Errorf("Coerce[int](%#v) = %d, %v, want %d", tt.v, got, err, tt.want)
+		}
+	}
+	for _, v := range []interface{}{3.5, "x", float64(math.MaxInt64) * 2, true} {
+		if got, err := Coerce[int](v); err == nil {
+			t.Errorf("Coerce[int](%#v) = %d, expected an error", v, got)
+		}
+	}
+	if got, err := Coerce[int8](float64(200)); err == nil {
+		t.Errorf("Coerce[int8](200) = %d, expected an overflow error", got)
+	}
+}
+
+func TestCoerceUints(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want uint64
+	}{
+		{json.Number("18446744073709551615"), math.MaxUint64},
+		{"18446744073709551615", math.MaxUint64},
+		{uint64(math.MaxUint64), math.MaxUint64},
+		{float64(1 << 63), 1 << 63},
+		{float64(3), 3},
+		{int64(5), 5},
+		{json.Number("0"), 0},
+	}
+	for _, tt := range tests {
+		got, err := Coerce[uint64](tt.v)
+		if err != nil || got != tt.want {
+			t.Errorf("Coerce[uint64](%#v) = %d, %v, want %d", tt.v, got, err, tt.want)
+		}
+	}
+	for _, v := range []interface{}{float64(-1), json.Number("-1"), "-1", int8(-1), json.Number("18446744073709551616"), float64(1 << 64), 1.5} {
+		if got, err := Coerce[uint64](v); err == nil {
+			t.Errorf("Coerce[uint64](%#v) = %d, expected an error", v, got)
+		}
+	}
+	if got, err := Coerce[uint8](json.Number("256")); err == nil {
+		t.Errorf("Coerce[uint8](256) = %d, expected an overflow error", got)
+	}
+}
+
+func TestCoerceOthers(t *testing.T) {
+	if got, err := Coerce[float64](json.Number("1.5")); err != nil || got != 1.5 {
+		t.Errorf("Coerce[float64] = %v, %v", got, err)
+	}
+	if got, err := Coerce[bool]("true"); err != nil || !got {
+		t.Errorf("Coerce[bool] = %v, %v", got, err)
+	}
+	if got, err := Coerce[string](float64(2.5)); err != nil || got != "2.5" {
+		t.Errorf("Coerce[string] = %q, %v", got, err)
+	}
+	type point struct{ X, Y int }
+	got, err := Coerce[point](map[string]interface{}{"X": float64(1), "Y": float64(2)})
+	if err != nil || !reflect.DeepEqual(got, point{1, 2}) {
+		t.Errorf("Coerce[point] = %v, %v", got, err)
+	}
+}
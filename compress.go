@@ -0,0 +1,51 @@
+package http_utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+)
+
+/*
+An http.RoundTripper gzipping request bodies larger than minSize bytes and setting Content-Encoding: gzip, inner nil for http.DefaultTransport.
+Only use it for servers that accept gzip request bodies, most do not by default.
+
+Any Content-Length header is removed and req.ContentLength set to the compressed size.
+Bodies already carrying a Content-Encoding, and bodies of minSize or less, are sent unchanged
+*/
+func GzipRequestTransport(inner http.RoundTripper, minSize int) http.RoundTripper {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+			return inner.RoundTrip(req)
+		}
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		out := req.Clone(req.Context())
+		if len(body) > minSize {
+			var compressed bytes.Buffer
+			zw := gzip.NewWriter(&compressed)
+			if _, err := zw.Write(body); err != nil {
+				return nil, err
+			}
+			if err := zw.Close(); err != nil {
+				return nil, err
+			}
+			body = compressed.Bytes()
+			out.Header.Set("Content-Encoding", "gzip")
+			out.Header.Del("Content-Length")
+		}
+		out.ContentLength = int64(len(body))
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+		return inner.RoundTrip(out)
+	})
+}
@@ -0,0 +1,95 @@
+package http_utils
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type receivedBody struct {
+	encoding string
+	length   int64
+	body     string
+}
+
+/* A server decompressing gzip request bodies, recording what it got */
+func gzipEchoServer(t *testing.T) (*httptest.Server, *receivedBody) {
+	got := &receivedBody{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.encoding, got.length = r.Header.Get("Content-Encoding"), r.ContentLength
+		var body io.Reader = r.Body
+		if got.encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("request body is not gzip: %v", err)
+				return
+			}
+			body = zr
+		}
+		raw, err := io.ReadAll(body)
+		if err != nil {
+			t.Errorf("reading body: %v", err)
+		}
+		got.body = string(raw)
+	}))
+	t.Cleanup(server.Close)
+	return server, got
+}
+
+func TestGzipRequestTransportCompressesLargeBodies(t *testing.T) {
+	server, got := gzipEchoServer(t)
+	client := &http.Client{Transport: GzipRequestTransport(nil, 100)}
+	payload := `{"items":"` + strings.Repeat("abc", 1000) + `"}`
+
+	response, err := client.Post(server.URL, "application/json", strings.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if got.encoding != "gzip" {
+		t.Errorf("Content-Encoding = %q, want gzip", got.encoding)
+	}
+	if got.body != payload {
+		t.Errorf("server decompressed %d bytes, want the %d byte payload", len(got.body), len(payload))
+	}
+	if got.length <= 0 || got.length >= int64(len(payload)) {
+		t.Errorf("Content-Length = %d, want the compressed size", got.length)
+	}
+}
+
+func TestGzipRequestTransportLeavesSmallBodies(t *testing.T) {
+	server, got := gzipEchoServer(t)
+	client := &http.Client{Transport: GzipRequestTransport(nil, 100)}
+	payload := strings.Repeat("a", 100) // minSize or less is sent as is
+
+	response, err := client.Post(server.URL, "text/plain", strings.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if got.encoding != "" || got.body != payload || got.length != 100 {
+		t.Errorf("got encoding %q, length %d, body %q", got.encoding, got.length, got.body)
+	}
+}
+
+func TestGzipRequestTransportKeepsExistingEncoding(t *testing.T) {
+	var encoding, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		encoding, body = r.Header.Get("Content-Encoding"), string(raw)
+	}))
+	defer server.Close()
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(strings.Repeat("x", 500)))
+	req.Header.Set("Content-Encoding", "br")
+	response, err := (&http.Client{Transport: GzipRequestTransport(nil, 100)}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if encoding != "br" || len(body) != 500 {
+		t.Errorf("got encoding %q and %d bytes, want the body untouched", encoding, len(body))
+	}
+}
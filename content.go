@@ -0,0 +1,55 @@
+package http_utils
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+/*
+Returns the Content-Type of a response, from the headers if set, otherwise sniffed from the body with http.DetectContentType
+*/
+func DetectContentType(headers http.Header, body []byte) string {
+	if contentType := headers.Get("Content-Type"); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(body)
+}
+
+/*
+Decodes a response body into dest based on its content type, i.e. from DetectContentType
+
+  - json (application/json, any +json suffix) : json.Unmarshal into dest
+
+  - xml (application/xml, text/xml, any +xml suffix) : xml.Unmarshal into dest
+
+  - text/* : dest must be *string or *[]byte
+
+anything else returns an error
+*/
+func DecodeBody(body []byte, contentType string, dest interface{}) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return err
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return json.Unmarshal(body, dest)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return xml.Unmarshal(body, dest)
+	case strings.HasPrefix(mediaType, "text/"):
+		switch d := dest.(type) {
+		case *string:
+			*d = string(body)
+		case *[]byte:
+			*d = append((*d)[:0], body...)
+		default:
+			return fmt.Errorf("cannot decode %s into %T, need *string or *[]byte", mediaType, dest)
+		}
+		return nil
+	}
+	return fmt.Errorf("no decoder for content type %s", mediaType)
+}
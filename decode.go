@@ -0,0 +1,102 @@
+package http_utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+/* Decodes json into a new T, with Unmarshal's UseNumber semantics, i.e. user, err := FromJSON[User](body) */
+func FromJSON[T any](data []byte) (T, error) {
+	var result T
+	err := Unmarshal(data, &result)
+	return result, err
+}
+
+/* FromJSON reading from r, decodes a single json value and leaves anything after it unread */
+func FromJSONReader[T any](r io.Reader) (T, error) {
+	return ReadJSON[T](r)
+}
+
+/* Functional option adjusting the json.Decoder of ReadJSON */
+type JSONDecodeOption func(*json.Decoder)
+
+/* Fails decoding with an *UnknownFieldError for objects with fields T does not have, as GetReqFromJSONStrict */
+func StrictJSON() JSONDecodeOption {
+	return func(d *json.Decoder) {
+		d.DisallowUnknownFields()
+	}
+}
+
+/*
+Decodes a single json value from r into a new T with UseNumber, i.e. user, err := ReadJSON[User](resp.Body, StrictJSON()).
+Anything after the value is left unread
+*/
+func ReadJSON[T any](r io.Reader, opts ...JSONDecodeOption) (T, error) {
+	var result T
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	for _, opt := range opts {
+		opt(decoder)
+	}
+	err := decoder.Decode(&result)
+	if field, ok := unknownFieldName(err); ok {
+		return result, &UnknownFieldError{Field: field}
+	}
+	return result, err
+}
+
+/* Returned by DecodePolymorphicArray for an element whose discriminator is missing or not in the registry */
+type DiscriminatorError struct {
+	Index int
+	Key   string
+	Value string // empty if the key was missing
+}
+
+func (e *DiscriminatorError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("element %d: missing discriminator %q", e.Index, e.Key)
+	}
+	return fmt.Sprintf("element %d: unregistered %s %q", e.Index, e.Key, e.Value)
+}
+
+/*
+Decodes a json array whose elements have different types, picked by a discriminator field, i.e.
+
+	items, err := DecodePolymorphicArray(body, "type", map[string]func() interface{}{
+		"card": func() interface{} { return &Card{} },
+		"bank": func() interface{} { return &BankAccount{} },
+	})
+
+Each factory returns a pointer for the element to be decoded into, the returned slice holds those pointers.
+Returns a *DiscriminatorError for an element with a missing or unregistered discriminator
+*/
+func DecodePolymorphicArray(data []byte, discriminatorKey string, registry map[string]func() interface{}) ([]interface{}, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return nil, err
+	}
+	decoded := make([]interface{}, 0, len(elements))
+	for i, element := range elements {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(element, &fields); err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		var discriminator string
+		if raw, ok := fields[discriminatorKey]; ok {
+			if err := json.Unmarshal(raw, &discriminator); err != nil {
+				return nil, fmt.Errorf("element %d: discriminator %q is not a string", i, discriminatorKey)
+			}
+		}
+		factory, ok := registry[discriminator]
+		if discriminator == "" || !ok {
+			return nil, &DiscriminatorError{Index: i, Key: discriminatorKey, Value: discriminator}
+		}
+		dest := factory()
+		if err := Unmarshal(element, dest); err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		decoded = append(decoded, dest)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,144 @@
+package http_utils
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httputil"
+)
+
+type dumpOptions struct {
+	redact bool
+}
+
+/* Option for DumpRequest, DumpResponse and DumpingTransport */
+type DumpOption func(*dumpOptions)
+
+/* Replaces the values of Authorization, Proxy-Authorization, Cookie and Set-Cookie headers with REDACTED in the dump */
+func RedactAuthorization() DumpOption {
+	return func(o *dumpOptions) {
+		o.redact = true
+	}
+}
+
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+func dumpHeader(h http.Header, o dumpOptions) http.Header {
+	if !o.redact {
+		return h
+	}
+	h = h.Clone()
+	for _, name := range redactedHeaders {
+		if _, ok := h[name]; ok {
+			h.Set(name, "REDACTED")
+		}
+	}
+	return h
+}
+
+func newDumpOptions(opts []DumpOption) dumpOptions {
+	var o dumpOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+/*
+Writes req in HTTP/1.1 wire format to w. req is not modified
+
+  - body <[]byte> : the request body, passed separately as req.Body can only be read once
+
+Works for both outgoing client requests and incoming server requests
+*/
+func DumpRequest(req *http.Request, body []byte, w io.Writer, opts ...DumpOption) error {
+	o := newDumpOptions(opts)
+	clone := req.Clone(req.Context())
+	clone.Header = dumpHeader(req.Header, o)
+	clone.Body = http.NoBody
+	if len(body) > 0 {
+		clone.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	clone.ContentLength = int64(len(body))
+
+	var dump []byte
+	var err error
+	if req.RequestURI != "" {
+		dump, err = httputil.DumpRequest(clone, true)
+	} else {
+		dump, err = httputil.DumpRequestOut(clone, true)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(dump)
+	return err
+}
+
+/*
+Writes resp in HTTP/1.1 wire format to w. resp.Body is read and replaced with an in memory copy, so it can still be read after
+*/
+func DumpResponse(resp *http.Response, w io.Writer, opts ...DumpOption) error {
+	o := newDumpOptions(opts)
+	var body []byte
+	if resp.Body != nil {
+		var err error
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+	}
+	clone := *resp
+	clone.Header = dumpHeader(resp.Header, o)
+	clone.Body = io.NopCloser(bytes.NewReader(body))
+
+	dump, err := httputil.DumpResponse(&clone, true)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(dump)
+	return err
+}
+
+/*
+An http.RoundTripper that dumps every request and response to Writer, i.e. for wire level debugging
+
+	client := &http.Client{Transport: &DumpingTransport{Writer: os.Stderr, Options: []DumpOption{RedactAuthorization()}}}
+
+Dump errors are ignored so debugging never fails a request
+*/
+type DumpingTransport struct {
+	Inner   http.RoundTripper // nil for http.DefaultTransport
+	Writer  io.Writer
+	Options []DumpOption
+}
+
+func (t *DumpingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	DumpRequest(req, body, t.Writer, t.Options...)
+	io.WriteString(t.Writer, "\n")
+
+	inner := t.Inner
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	resp, err := inner.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	DumpResponse(resp, t.Writer, t.Options...)
+	io.WriteString(t.Writer, "\n")
+	return resp, nil
+}
@@ -0,0 +1,57 @@
+package http_utils
+
+import "encoding/json"
+
+/*
+Decodes the value at dataKey of a json envelope into a T, i.e. for {"data": {...}, "meta": {...}}
+
+	user, err := Unwrap[User](body, "data")
+
+Returns a *PathNotFoundError if dataKey is missing
+*/
+func Unwrap[T any](body []byte, dataKey string) (T, error) {
+	var result T
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return result, err
+	}
+	data, ok := envelope[dataKey]
+	if !ok {
+		return result, &PathNotFoundError{Path: dataKey, Segment: dataKey}
+	}
+	err := Unmarshal(data, &result)
+	return result, err
+}
+
+/*
+Decodes a list envelope, the items array at dataKey into a []T and the pagination metadata at metaKey into metaDest, i.e.
+
+	var meta struct{ NextCursor string `json:"next_cursor"` }
+	items, err := UnwrapPaginated[Order](body, "data", "meta", &meta)
+
+metaDest may be nil to skip the metadata. Returns a *PathNotFoundError if either key is missing
+*/
+func UnwrapPaginated[T any](body []byte, dataKey string, metaKey string, metaDest interface{}) ([]T, error) {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	data, ok := envelope[dataKey]
+	if !ok {
+		return nil, &PathNotFoundError{Path: dataKey, Segment: dataKey}
+	}
+	var items []T
+	if err := Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	if metaDest != nil {
+		meta, ok := envelope[metaKey]
+		if !ok {
+			return nil, &PathNotFoundError{Path: metaKey, Segment: metaKey}
+		}
+		if err := Unmarshal(meta, metaDest); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
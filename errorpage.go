@@ -0,0 +1,85 @@
+package http_utils
+
+import (
+	"html"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/* Writes an error response, i.e. from NewErrorHandler */
+type ErrorRenderer func(statusCode int, message string, w http.ResponseWriter, r *http.Request)
+
+/* Data passed to NewErrorHandler templates */
+type ErrorPageData struct {
+	StatusCode int
+	Status     string // reason phrase i.e. "Not Found"
+	Message    string
+}
+
+/*
+Returns an ErrorRenderer that writes an HTML error page or a json {"error": message} body depending on the request's Accept header,
+for services serving both browsers and API clients
+
+  - templates <map[int]string> : html/template source per status code, executed with ErrorPageData.
+    Codes without a template get a minimal page. Panics if a template does not parse, like regexp.MustCompile
+
+  - jsonErrors <bool> : the format when Accept prefers neither, i.e. a missing or wildcard Accept, true for json and false for HTML
+*/
+func NewErrorHandler(templates map[int]string, jsonErrors bool) ErrorRenderer {
+	parsed := make(map[int]*template.Template, len(templates))
+	for code, src := range templates {
+		parsed[code] = template.Must(template.New(strconv.Itoa(code)).Parse(src))
+	}
+	return func(statusCode int, message string, w http.ResponseWriter, r *http.Request) {
+		if !prefersHTML(r.Header.Get("Accept"), !jsonErrors) {
+			WriteJSON(w, statusCode, map[string]string{"error": message})
+			return
+		}
+		data := ErrorPageData{StatusCode: statusCode, Status: http.StatusText(statusCode), Message: message}
+		tmpl, ok := parsed[statusCode]
+		if !ok {
+			WriteHTML(w, statusCode, "<!DOCTYPE html><html><head><title>"+strconv.Itoa(statusCode)+" "+html.EscapeString(data.Status)+
+				"</title></head><body><h1>"+html.EscapeString(data.Status)+"</h1><p>"+html.EscapeString(message)+"</p></body></html>")
+			return
+		}
+		var page strings.Builder
+		if err := tmpl.Execute(&page, data); err != nil {
+			getLogger().Printf("error page template %d: %v", statusCode, err)
+			WritePlainText(w, statusCode, message)
+			return
+		}
+		WriteHTML(w, statusCode, page.String())
+	}
+}
+
+/* Compares the best quality factors for HTML and json in an Accept header, def when they tie */
+func prefersHTML(accept string, def bool) bool {
+	var htmlQ, jsonQ float64
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if name, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.EqualFold(name, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		switch mediaType = strings.ToLower(strings.TrimSpace(mediaType)); {
+		case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+			if q > htmlQ {
+				htmlQ = q
+			}
+		case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+			if q > jsonQ {
+				jsonQ = q
+			}
+		}
+	}
+	if htmlQ == jsonQ {
+		return def
+	}
+	return htmlQ > jsonQ
+}
@@ -0,0 +1,52 @@
+package http_utils
+
+import (
+	"context"
+	"sync"
+)
+
+/* One request to send alongside others, the arguments of HttpPostReqContext as a value */
+type FanOutRequest struct {
+	Config     *ClientConfig // nil for the defaults
+	Method     string
+	URL        string
+	Payload    interface{}
+	ReqHeaders []ReqHeader
+	AddHeaders []ReqHeader
+}
+
+func (f FanOutRequest) do(ctx context.Context) (*Response, error) {
+	return HttpPostReqContext(ctx, f.Config, f.Method, f.Payload, f.URL, f.ReqHeaders, f.AddHeaders)
+}
+
+/* Both sides of a TeeRequestDetailed */
+type TeeResult struct {
+	Primary    *Response
+	PrimaryErr error
+	Shadow     *Response
+	ShadowErr  error
+}
+
+/* Sends primary and shadow concurrently and waits for both, returning each response and error */
+func TeeRequestDetailed(ctx context.Context, primary FanOutRequest, shadow FanOutRequest) TeeResult {
+	var result TeeResult
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		result.Shadow, result.ShadowErr = shadow.do(ctx)
+	}()
+	result.Primary, result.PrimaryErr = primary.do(ctx)
+	wg.Wait()
+	return result
+}
+
+/*
+Dual-writes to a primary and a shadow endpoint, i.e. during a migration. Both are sent concurrently and both responses returned,
+the returned error is the primary's only, a failed shadow request just gives a nil shadow response.
+Use TeeRequestDetailed to get the shadow error for comparison logging
+*/
+func TeeRequest(ctx context.Context, primary FanOutRequest, shadow FanOutRequest) (*Response, *Response, error) {
+	result := TeeRequestDetailed(ctx, primary, shadow)
+	return result.Primary, result.Shadow, result.PrimaryErr
+}
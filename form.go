@@ -0,0 +1,70 @@
+package http_utils
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+)
+
+/*
+Builds a multipart/form-data body, i.e.
+
+	body, contentType, err := NewFormBuilder().AddField("name", "report").AddFile("file", "report.csv", f).Build()
+
+The first error is kept and returned by Build, later calls are then ignored
+*/
+type FormBuilder struct {
+	body   *bytes.Buffer
+	writer *multipart.Writer
+	err    error
+}
+
+func NewFormBuilder() *FormBuilder {
+	body := &bytes.Buffer{}
+	return &FormBuilder{body: body, writer: multipart.NewWriter(body)}
+}
+
+func (f *FormBuilder) AddField(name, value string) *FormBuilder {
+	if f.err == nil {
+		f.err = f.writer.WriteField(name, value)
+	}
+	return f
+}
+
+/* Adds a file part, r is read to the end straight away */
+func (f *FormBuilder) AddFile(fieldName, fileName string, r io.Reader) *FormBuilder {
+	if f.err != nil {
+		return f
+	}
+	part, err := f.writer.CreateFormFile(fieldName, fileName)
+	if err != nil {
+		f.err = err
+		return f
+	}
+	_, f.err = io.Copy(part, r)
+	return f
+}
+
+/* AddFile from a file on disk, named by its base name. Errors opening the file are returned here rather than from Build */
+func (f *FormBuilder) AddFileFromPath(fieldName, filePath string) (*FormBuilder, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return f, err
+	}
+	defer file.Close()
+	f.AddFile(fieldName, filepath.Base(filePath), file)
+	return f, f.err
+}
+
+/* Finishes the body, returning it and its Content-Type including the boundary */
+func (f *FormBuilder) Build() (*bytes.Buffer, string, error) {
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	if err := f.writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return f.body, f.writer.FormDataContentType(), nil
+}
@@ -0,0 +1,72 @@
+package http_utils
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormBuilderMultipartStructure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("from disk"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	builder, err := NewFormBuilder().
+		AddField("name", "report").
+		AddFile("file", "report.csv", strings.NewReader("a,b\n1,2\n")).
+		AddFileFromPath("notes", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, contentType, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		t.Fatalf("content type %q: %s %v %v", contentType, mediaType, params, err)
+	}
+	want := []struct{ formName, fileName, body string }{
+		{"name", "", "report"},
+		{"file", "report.csv", "a,b\n1,2\n"},
+		{"notes", "notes.txt", "from disk"},
+	}
+	reader := multipart.NewReader(body, params["boundary"])
+	for i, w := range want {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		got, _ := io.ReadAll(part)
+		if part.FormName() != w.formName || part.FileName() != w.fileName || string(got) != w.body {
+			t.Errorf("part %d = %q %q %q, want %q %q %q", i, part.FormName(), part.FileName(), got, w.formName, w.fileName, w.body)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("expected %d parts, next part err = %v", len(want), err)
+	}
+}
+
+func TestFormBuilderMissingFile(t *testing.T) {
+	_, err := NewFormBuilder().AddFileFromPath("file", filepath.Join(t.TempDir(), "missing.txt"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("err = %v, want os.ErrNotExist", err)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("read failed") }
+
+func TestFormBuilderKeepsFirstError(t *testing.T) {
+	_, _, err := NewFormBuilder().AddFile("file", "f.txt", failingReader{}).AddField("name", "x").Build()
+	if err == nil || err.Error() != "read failed" {
+		t.Errorf("err = %v, want the read error", err)
+	}
+}
@@ -0,0 +1,95 @@
+package http_utils
+
+/* Returns a new slice with fn applied to every element of slice */
+func Map[T, U any](slice []T, fn func(T) U) []U {
+	mapped := make([]U, 0, len(slice))
+	for _, v := range slice {
+		mapped = append(mapped, fn(v))
+	}
+	return mapped
+}
+
+/* Returns a new slice with only the elements of slice that fn returns true for */
+func Filter[T any](slice []T, fn func(T) bool) []T {
+	var filtered []T
+	for _, v := range slice {
+		if fn(v) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+/* Folds slice into a single value, starting from initial, i.e. summing totals across pages */
+func Reduce[T, U any](slice []T, initial U, fn func(U, T) U) U {
+	acc := initial
+	for _, v := range slice {
+		acc = fn(acc, v)
+	}
+	return acc
+}
+
+/*
+Splits slice into batches of at most size elements, i.e. for write APIs with a maximum batch size.
+The batches share slice's backing array but are capped so appending to one does not overwrite the next.
+A size less than 1 is treated as 1
+*/
+func Chunk[T any](slice []T, size int) [][]T {
+	if size < 1 {
+		size = 1
+	}
+	chunks := make([][]T, 0, (len(slice)+size-1)/size)
+	for start := 0; start < len(slice); start += size {
+		end := start + size
+		if end > len(slice) {
+			end = len(slice)
+		}
+		chunks = append(chunks, slice[start:end:end])
+	}
+	return chunks
+}
+
+/* A value or an error, for callers that prefer passing results around over (value, error) pairs */
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+/* Wraps the (value, error) return of a call, i.e. ResultOf(HttpPostReqContext(...)) */
+func ResultOf[T any](val T, err error) Result[T] {
+	return Result[T]{Value: val, Err: err}
+}
+
+func (r Result[T]) Ok() bool {
+	return r.Err == nil
+}
+
+/* Returns Value, panics with Err if there is one */
+func (r Result[T]) Unwrap() T {
+	if r.Err != nil {
+		panic(r.Err)
+	}
+	return r.Value
+}
+
+/* Returns Value, or def if there is an error */
+func (r Result[T]) UnwrapOr(def T) T {
+	if r.Err != nil {
+		return def
+	}
+	return r.Value
+}
+
+/* Returns a pointer to v, for filling request struct fields from literals i.e. req.Symbol = Ptr("TSLA") */
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+/* Ptr, but nil when v is the zero value, so zero values are left out by RequestStructToquery i.e. req.Page = PtrOrNil(page) */
+func PtrOrNil[T comparable](v T) *T {
+	var zero T
+	if v == zero {
+		return nil
+	}
+	return &v
+}
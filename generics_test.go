@@ -0,0 +1,45 @@
+package http_utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name  string
+		slice []int
+		size  int
+		want  [][]int
+	}{
+		{"empty slice", []int{}, 3, [][]int{}},
+		{"nil slice", nil, 3, [][]int{}},
+		{"size larger than slice", []int{1, 2}, 5, [][]int{{1, 2}}},
+		{"size of one", []int{1, 2, 3}, 1, [][]int{{1}, {2}, {3}}},
+		{"size equal to len", []int{1, 2, 3}, 3, [][]int{{1, 2, 3}}},
+		{"uneven last chunk", []int{1, 2, 3, 4, 5}, 2, [][]int{{1, 2}, {3, 4}, {5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Chunk(tt.slice, tt.size); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Chunk(%v, %d) = %v, want %v", tt.slice, tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkAppendDoesNotOverwriteNext(t *testing.T) {
+	chunks := Chunk([]int{1, 2, 3, 4}, 2)
+	_ = append(chunks[0], 99)
+	if chunks[1][0] != 3 {
+		t.Errorf("appending to the first chunk changed the second to %v", chunks[1])
+	}
+}
+
+func TestChunkSizeBelowOneIsOne(t *testing.T) {
+	for _, size := range []int{0, -3} {
+		if got := Chunk([]int{1, 2}, size); !reflect.DeepEqual(got, [][]int{{1}, {2}}) {
+			t.Errorf("Chunk size %d = %v, want batches of 1", size, got)
+		}
+	}
+}
@@ -1,3 +1,10 @@
 module github.com/rogue-syntax/http_utils
 
 go 1.21.0
+
+require (
+	golang.org/x/net v0.25.0
+	golang.org/x/time v0.5.0
+)
+
+require golang.org/x/text v0.15.0 // indirect
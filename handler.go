@@ -0,0 +1,70 @@
+package http_utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+/*
+Builds an http.Handler from a typed function, so handler logic can be tested without a ResponseWriter, i.e.
+
+	mux.Handle("/users", Handler(createUser, nil))
+
+	func createUser(ctx context.Context, req CreateUserRequest) (*User, error) { ... }
+
+The arguments are
+
+  - fn <func(context.Context, Req) (Resp, error)> : called with the request context and the decoded request, its result is sent with WriteJSON and a 200
+
+  - decode <func(*http.Request) (Req, error)> : builds the Req, nil to decode the json body with GetReqFromJSON.
+    A decode error responds 400 with the error message
+
+Errors from fn become a json {"error": message} response. An *HTTPError keeps its StatusCode, ErrEmptyBody and *UnknownFieldError
+give 400, context.DeadlineExceeded gives 504 and anything else 500. 5xx responses send only the status text, so internal
+details are not leaked to clients
+*/
+func Handler[Req any, Resp any](fn func(context.Context, Req) (Resp, error), decode func(*http.Request) (Req, error)) http.Handler {
+	if decode == nil {
+		decode = func(r *http.Request) (Req, error) {
+			var req Req
+			err := GetReqFromJSON(r, &req)
+			return req, err
+		}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := decode(r)
+		if err != nil {
+			writeHandlerError(w, http.StatusBadRequest, err)
+			return
+		}
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			writeHandlerError(w, handlerErrorStatus(err), err)
+			return
+		}
+		WriteJSON(w, http.StatusOK, resp)
+	})
+}
+
+func handlerErrorStatus(err error) int {
+	var httpErr *HTTPError
+	var unknownField *UnknownFieldError
+	switch {
+	case errors.As(err, &httpErr) && httpErr.StatusCode >= 400:
+		return int(httpErr.StatusCode)
+	case errors.Is(err, ErrEmptyBody), errors.As(err, &unknownField):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+func writeHandlerError(w http.ResponseWriter, statusCode int, err error) {
+	message := err.Error()
+	if statusCode >= 500 {
+		message = http.StatusText(statusCode)
+	}
+	WriteJSON(w, statusCode, map[string]string{"error": message})
+}
@@ -0,0 +1,235 @@
+package http_utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+/*
+Fluent builder for []ReqHeader, i.e.
+
+	headers := NewHeaders().ContentTypeJSON().Authorization(token).Build()
+
+The zero value is ready to use. Header names are compared case-insensitively by Set and Del.
+*/
+type Headers struct {
+	headers []ReqHeader
+}
+
+func NewHeaders() *Headers {
+	return &Headers{}
+}
+
+/* Appends a header, keeping any existing headers of the same name. Note that HttpPostReq sets headers in order, so the last value wins */
+func (h *Headers) Add(name, value string) *Headers {
+	h.headers = append(h.headers, ReqHeader{HeaderName: name, HeaderValue: value})
+	return h
+}
+
+/* Replaces any existing headers of the same name with a single header */
+func (h *Headers) Set(name, value string) *Headers {
+	return h.Del(name).Add(name, value)
+}
+
+/* Removes all headers of the given name */
+func (h *Headers) Del(name string) *Headers {
+	canonical := http.CanonicalHeaderKey(name)
+	kept := h.headers[:0]
+	for _, header := range h.headers {
+		if http.CanonicalHeaderKey(header.HeaderName) != canonical {
+			kept = append(kept, header)
+		}
+	}
+	h.headers = kept
+	return h
+}
+
+/* Sets "Content-Type: application/json; charset=utf-8" */
+func (h *Headers) ContentTypeJSON() *Headers {
+	return h.Set("Content-Type", "application/json; charset=utf-8")
+}
+
+/* Sets "Authorization: Bearer <token>" */
+func (h *Headers) Authorization(token string) *Headers {
+	return h.Set("Authorization", "Bearer "+token)
+}
+
+/* Returns a copy of the built headers, safe to use as reqHeaders or addHeaders */
+func (h *Headers) Build() []ReqHeader {
+	built := make([]ReqHeader, len(h.headers))
+	copy(built, h.headers)
+	return built
+}
+
+/*
+Merges two header slices into one with a single header per name, names are compared in canonical form.
+Values from override win over base, and later duplicates within a slice win over earlier ones.
+Order follows the first appearance of each name, base first.
+*/
+func MergeHeaders(base, override []ReqHeader) []ReqHeader {
+	index := make(map[string]int, len(base)+len(override))
+	var merged []ReqHeader
+	for _, headers := range [][]ReqHeader{base, override} {
+		for _, header := range headers {
+			canonical := http.CanonicalHeaderKey(header.HeaderName)
+			if i, ok := index[canonical]; ok {
+				merged[i] = header
+				continue
+			}
+			index[canonical] = len(merged)
+			merged = append(merged, header)
+		}
+	}
+	return merged
+}
+
+/* Returns a "User-Agent: name/version" header */
+func UserAgentHeader(name, version string) ReqHeader {
+	return ReqHeader{HeaderName: "User-Agent", HeaderValue: name + "/" + version}
+}
+
+var defaultUserAgent atomic.Value // string
+
+/*
+Sets a "User-Agent: name/version" header on every request made through HttpPostReq
+that does not already set its own User-Agent. Safe to call concurrently with requests
+*/
+func SetDefaultUserAgent(name, version string) {
+	defaultUserAgent.Store(UserAgentHeader(name, version).HeaderValue)
+}
+
+func getDefaultUserAgent() string {
+	userAgent, _ := defaultUserAgent.Load().(string)
+	return userAgent
+}
+
+/* "Accept: application/json" */
+func AcceptJSON() ReqHeader {
+	return ReqHeader{HeaderName: "Accept", HeaderValue: "application/json"}
+}
+
+/* "Accept: application/xml" */
+func AcceptXML() ReqHeader {
+	return ReqHeader{HeaderName: "Accept", HeaderValue: "application/xml"}
+}
+
+/* "Accept: text/plain" */
+func AcceptPlainText() ReqHeader {
+	return ReqHeader{HeaderName: "Accept", HeaderValue: "text/plain"}
+}
+
+/* Accepts any media type */
+func AcceptAny() ReqHeader {
+	return ReqHeader{HeaderName: "Accept", HeaderValue: "*/*"}
+}
+
+/* A media type and its quality factor Q, 0.0 - 1.0, for AcceptWithQuality */
+type QualityType struct {
+	MediaType string
+	Q         float32
+}
+
+/*
+Builds an Accept header with quality factors, i.e.
+
+	AcceptWithQuality(QualityType{"application/json", 1}, QualityType{"text/plain", 0.5})
+	// Accept: application/json, text/plain;q=0.5
+
+Q is clamped to 0.0 - 1.0 and rounded to 3 decimal places, q=1 is left off as it is the default
+*/
+func AcceptWithQuality(types ...QualityType) ReqHeader {
+	values := make([]string, 0, len(types))
+	for _, t := range types {
+		q := t.Q
+		if q < 0 {
+			q = 0
+		}
+		if q >= 1 {
+			values = append(values, t.MediaType)
+			continue
+		}
+		qStr := strings.TrimRight(strconv.FormatFloat(float64(q), 'f', 3, 32), "0")
+		qStr = strings.TrimSuffix(qStr, ".")
+		values = append(values, t.MediaType+";q="+qStr)
+	}
+	return ReqHeader{HeaderName: "Accept", HeaderValue: strings.Join(values, ", ")}
+}
+
+/*
+Builds an http.Header from headers in one pass, canonicalising each name once.
+Like request.Header.Set, a later header of the same name replaces an earlier one
+*/
+func ToHTTPHeader(headers []ReqHeader) http.Header {
+	h := make(http.Header, len(headers))
+	for _, header := range headers {
+		h[textproto.CanonicalMIMEHeaderKey(header.HeaderName)] = []string{header.HeaderValue}
+	}
+	return h
+}
+
+/* Returns a new slice with the same headers, so appending to either never affects the other. nil stays nil */
+func CopyReqHeaders(headers []ReqHeader) []ReqHeader {
+	if headers == nil {
+		return nil
+	}
+	copied := make([]ReqHeader, len(headers))
+	copy(copied, headers)
+	return copied
+}
+
+/* Deep copies h, including each multi value slice, so the copy can be changed without touching h. nil stays nil */
+func CopyHTTPHeaders(h http.Header) http.Header {
+	return h.Clone()
+}
+
+/* Returned by ValidateHeaderSize, Headers names every header whose value is over MaxValueBytes */
+type HeaderTooLargeError struct {
+	Headers       []string
+	MaxValueBytes int
+}
+
+func (e *HeaderTooLargeError) Error() string {
+	return fmt.Sprintf("header values over %d bytes: %s", e.MaxValueBytes, strings.Join(e.Headers, ", "))
+}
+
+/* Checks no header value is longer than maxValueBytes, as proxies and servers often reject large headers (8KB is a common limit) */
+func ValidateHeaderSize(headers []ReqHeader, maxValueBytes int) error {
+	var tooLarge []string
+	for _, header := range headers {
+		if len(header.HeaderValue) > maxValueBytes {
+			tooLarge = append(tooLarge, header.HeaderName)
+		}
+	}
+	if len(tooLarge) > 0 {
+		return &HeaderTooLargeError{Headers: tooLarge, MaxValueBytes: maxValueBytes}
+	}
+	return nil
+}
+
+/*
+Returns a copy of headers with values cut to at most maxValueBytes, without splitting a UTF-8 character.
+Only for informational headers, a truncated Authorization (or any signed or token header) will just fail,
+so always check those with ValidateHeaderSize instead
+*/
+func TruncateHeaders(headers []ReqHeader, maxValueBytes int) []ReqHeader {
+	if maxValueBytes < 0 {
+		maxValueBytes = 0
+	}
+	truncated := CopyReqHeaders(headers)
+	for i, header := range truncated {
+		if len(header.HeaderValue) <= maxValueBytes {
+			continue
+		}
+		cut := maxValueBytes
+		for cut > 0 && !utf8.RuneStart(header.HeaderValue[cut]) {
+			cut--
+		}
+		truncated[i].HeaderValue = header.HeaderValue[:cut]
+	}
+	return truncated
+}
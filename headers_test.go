@@ -0,0 +1,78 @@
+package http_utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDefaultUserAgent(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+	t.Cleanup(func() { defaultUserAgent.Store("") })
+
+	SetDefaultUserAgent("myapp", "1.2.0")
+	if _, _, err := HttpPostReq("GET", nil, server.URL, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got != "myapp/1.2.0" {
+		t.Errorf("User-Agent = %q, want myapp/1.2.0", got)
+	}
+
+	own := []ReqHeader{UserAgentHeader("other", "9")}
+	if _, _, err := HttpPostReq("GET", nil, server.URL, own, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got != "other/9" {
+		t.Errorf("User-Agent = %q, want the request's own other/9", got)
+	}
+}
+
+func TestToHTTPHeaderCanonicalises(t *testing.T) {
+	h := ToHTTPHeader([]ReqHeader{
+		{HeaderName: "content-type", HeaderValue: "application/json"},
+		{HeaderName: "X-REQUEST-ID", HeaderValue: "abc"},
+		{HeaderName: "x-request-id", HeaderValue: "def"},
+	})
+	if len(h) != 2 {
+		t.Fatalf("got %d keys, want 2: %v", len(h), h)
+	}
+	if got := h["Content-Type"]; len(got) != 1 || got[0] != "application/json" {
+		t.Errorf("Content-Type = %v", got)
+	}
+	if got := h["X-Request-Id"]; len(got) != 1 || got[0] != "def" {
+		t.Errorf("X-Request-Id = %v, want the last value only", got)
+	}
+}
+
+func benchmarkHeaders(n int) []ReqHeader {
+	headers := make([]ReqHeader, n)
+	for i := range headers {
+		headers[i] = ReqHeader{HeaderName: fmt.Sprintf("x-custom-header-%d", i), HeaderValue: "value"}
+	}
+	return headers
+}
+
+func BenchmarkToHTTPHeader(b *testing.B) {
+	headers := benchmarkHeaders(20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ToHTTPHeader(headers)
+	}
+}
+
+/* The per header Header.Set loop ToHTTPHeader replaces */
+func BenchmarkHeaderSet(b *testing.B) {
+	headers := benchmarkHeaders(20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		h := http.Header{}
+		for _, header := range headers {
+			h.Set(header.HeaderName, header.HeaderValue)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package http_utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+/* Result of checking one url */
+type HealthEntry struct {
+	URL        string
+	StatusCode StatusCode // 0 if no response arrived
+	Latency    time.Duration
+	Err        error
+	Healthy    bool
+}
+
+/* Results of CheckHealth, Entries are in the order of the urls passed in */
+type HealthReport struct {
+	Entries []HealthEntry
+	Healthy bool // true if every entry is healthy
+}
+
+/*
+Sends a GET to each url concurrently and reports whether each responded with expectedStatus within timeout
+
+  - ctx <context.Context> : cancels all checks
+
+  - expectedStatus <int> : the status code a healthy url responds with, i.e. 200
+
+  - timeout <time.Duration> : limit per url, 0 for none
+*/
+func CheckHealth(ctx context.Context, urls []string, expectedStatus int, timeout time.Duration) HealthReport {
+	report := HealthReport{Entries: make([]HealthEntry, len(urls)), Healthy: true}
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			report.Entries[i] = checkOne(ctx, url, expectedStatus, timeout)
+		}(i, url)
+	}
+	wg.Wait()
+	for _, entry := range report.Entries {
+		if !entry.Healthy {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+func checkOne(ctx context.Context, url string, expectedStatus int, timeout time.Duration) HealthEntry {
+	entry := HealthEntry{URL: url}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		entry.Err = err
+		return entry
+	}
+	start := time.Now()
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		entry.Latency = time.Since(start)
+		entry.Err = err
+		return entry
+	}
+	io.Copy(io.Discard, response.Body)
+	response.Body.Close()
+	entry.Latency = time.Since(start)
+	entry.StatusCode = StatusCode(response.StatusCode)
+	if response.StatusCode != expectedStatus {
+		entry.Err = fmt.Errorf("expected status %d, got %s", expectedStatus, response.Status)
+		return entry
+	}
+	entry.Healthy = true
+	return entry
+}
@@ -0,0 +1,49 @@
+package http_utils
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+/*
+An *http.Client that negotiates HTTP/2 over TLS via ALPN, falling back to HTTP/1.1 if the server does not offer h2
+
+  - tlsConfig <*tls.Config> : nil for the default tls config
+
+Errors if http2.ConfigureTransport rejects the transport
+*/
+func NewHTTP2Client(tlsConfig *tls.Config) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig.Clone()
+	}
+	if err := http2.ConfigureTransport(transport); err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+/*
+An *http.Client that speaks cleartext HTTP/2 (h2c) with prior knowledge, for development servers wrapped with NewH2CHandler.
+Only use this for http:// urls, the connection is not encrypted
+*/
+func NewH2CClient() *http.Client {
+	dialer := &net.Dialer{}
+	transport := &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+	return &http.Client{Transport: transport}
+}
+
+/* Wraps a handler so a plain http server also accepts cleartext HTTP/2 (h2c) requests i.e. from NewH2CClient */
+func NewH2CHandler(h http.Handler) http.Handler {
+	return h2c.NewHandler(h, &http2.Server{})
+}
@@ -2,13 +2,18 @@ package http_utils
 
 import (
 	"bytes"
+	"context"
+	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
 	"net/http"
+	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -21,6 +26,15 @@ func Marshal(i interface{}) ([]byte, error) {
 	return bytes.TrimRight(buffer.Bytes(), "\n"), err
 }
 
+/*
+Counterpart to Marshal, decodes json into v with UseNumber so large integers are not rounded through float64
+*/
+func Unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
 type ReqHeader struct {
 	HeaderName  string
 	HeaderValue string
@@ -43,62 +57,155 @@ Returns :
   - error
 */
 func HttpPostReq(method string, payload interface{}, url string, reqHeaders []ReqHeader, addHeaders []ReqHeader) ([]byte, string, error) {
+	return HttpPostReqWithConfig(nil, method, payload, url, reqHeaders, addHeaders)
+}
+
+/*
+HttpPostReq with a *ClientConfig, see HttpPostReq for the other arguments and return values
+
+  - cfg <*ClientConfig> : nil for the defaults, cfg.Serialiser encodes the payload and sets the default Content-Type
+*/
+func HttpPostReqWithConfig(cfg *ClientConfig, method string, payload interface{}, url string, reqHeaders []ReqHeader, addHeaders []ReqHeader) ([]byte, string, error) {
+	var returnByes []byte
+	response, err := HttpPostReqContext(context.Background(), cfg, method, payload, url, reqHeaders, addHeaders)
+	if err != nil {
+		return returnByes, "", err
+	}
+	return response.Body, response.Status, nil
+}
+
+/*
+HttpPostReqWithConfig bound to ctx, returning the whole *Response rather than just the body and status.
+See HttpPostReq for the other arguments
+*/
+func HttpPostReqContext(ctx context.Context, cfg *ClientConfig, method string, payload interface{}, url string, reqHeaders []ReqHeader, addHeaders []ReqHeader) (*Response, error) {
+	serialiser := cfg.serialiser()
 	if reqHeaders == nil {
 		defaultHeader := []ReqHeader{
-			{HeaderName: "Content-Type", HeaderValue: "application/json; charset=utf-8"},
+			{HeaderName: "Content-Type", HeaderValue: serialiser.ContentType()},
 			{HeaderName: "Accept", HeaderValue: "application/json"},
 		}
 		reqHeaders = defaultHeader
 	}
 	if addHeaders != nil {
-		reqHeaders = append(reqHeaders, addHeaders...)
+		// copy first, appending in place could overwrite the spare capacity of a slice the caller shares
+		reqHeaders = append(CopyReqHeaders(reqHeaders), addHeaders...)
 	}
-	var returnByes []byte
 	var reqBytes []byte
 	var err error
 	if payload != nil {
-		reqBytes, err = json.Marshal(&payload)
+		reqBytes, err = serialiser.Marshal(payload)
 		if err != nil {
-			return returnByes, "", err
+			return nil, err
 		}
 	}
 
-	request, err := http.NewRequest(method, url, bytes.NewBuffer(reqBytes))
+	request, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(reqBytes))
 	if err != nil {
-		return returnByes, "", err
+		return nil, err
 	}
 
-	for i := 0; i < len(reqHeaders); i++ {
-		request.Header.Set(reqHeaders[i].HeaderName, reqHeaders[i].HeaderValue)
+	request.Header = ToHTTPHeader(reqHeaders)
+	if userAgent := getDefaultUserAgent(); userAgent != "" && request.Header.Get("User-Agent") == "" {
+		request.Header.Set("User-Agent", userAgent)
 	}
 
-	client := &http.Client{}
-	response, err := client.Do(request)
+	response, err := cfg.httpClient().Do(request)
 	if err != nil {
-		return returnByes, "", err
+		return nil, err
 	}
 
 	defer response.Body.Close()
-	rBody, err := io.ReadAll(response.Body)
+	var body io.Reader = response.Body
+	maxBytes := cfg.maxResponseBytes()
+	if maxBytes > 0 {
+		// read one byte past the limit to tell a body of exactly maxBytes from a larger one
+		body = io.LimitReader(response.Body, maxBytes+1)
+	}
+	rBody, err := io.ReadAll(body)
 	if err != nil {
-		return returnByes, "", err
+		return nil, err
+	}
+	if maxBytes > 0 && int64(len(rBody)) > maxBytes {
+		return nil, &ResponseTooLargeError{URL: url, Limit: maxBytes, Body: rBody[:maxBytes]}
 	}
 
-	return rBody, response.Status, nil
+	return &Response{
+		StatusCode: StatusCode(response.StatusCode),
+		Status:     response.Status,
+		Header:     response.Header,
+		Body:       rBody,
+	}, nil
 }
 
+/* Returned instead of a json EOF error when there is no body to decode, i.e. a 204 No Content response */
+var ErrEmptyBody = errors.New("empty body")
+
 /*
-Decodes json from an incoming request body to an object interface{}
+Decodes json from an incoming request body to an object interface{}, returns ErrEmptyBody when the body is empty
 */
 func GetReqFromJSON(r *http.Request, reqObj interface{}) error {
+	if r.Body == nil {
+		return ErrEmptyBody
+	}
 	decoder := json.NewDecoder(r.Body)
 	err := decoder.Decode(&reqObj)
 	if err != nil {
+		if err == io.EOF {
+			return ErrEmptyBody
+		}
 		return err
 	}
 	return nil
 }
 
+/* Returned by GetReqFromJSONStrict when the body has a field reqObj does not */
+type UnknownFieldError struct {
+	Field string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q", e.Field)
+}
+
+/*
+GetReqFromJSON that rejects fields not present in reqObj, returning an *UnknownFieldError naming the field
+so the caller can respond 400 with it
+*/
+func GetReqFromJSONStrict(r *http.Request, reqObj interface{}) error {
+	if r.Body == nil {
+		return ErrEmptyBody
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(reqObj)
+	if err != nil {
+		if err == io.EOF {
+			return ErrEmptyBody
+		}
+		if field, ok := unknownFieldName(err); ok {
+			return &UnknownFieldError{Field: field}
+		}
+		return err
+	}
+	return nil
+}
+
+/* The field named by a json.Decoder DisallowUnknownFields error, encoding/json has no typed error for this, only `json: unknown field "name"` */
+func unknownFieldName(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	field, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return "", false
+	}
+	if unquoted, uerr := strconv.Unquote(field); uerr == nil {
+		field = unquoted
+	}
+	return field, true
+}
+
 /* Camel case to snake case */
 var matchFirstCap = regexp.MustCompile("(.)([A-Z][a-z]+)")
 var matchAllCap = regexp.MustCompile("([a-z0-9])([A-Z])")
@@ -114,7 +221,30 @@ func GetFieldType(field reflect.Value) string {
 	return fmt.Sprintf("%s", field.Type())
 }
 
+/*
+Returned by GetAndAppendQueriesWithWarning for a field of a type it has no case for, the value was still appended using fmt.Sprintf("%v"),
+so this is a warning to log rather than a failure
+*/
+type UnsupportedTypeWarning struct {
+	FieldName string
+	FieldType string
+}
+
+func (w *UnsupportedTypeWarning) Error() string {
+	return fmt.Sprintf("query field %s: unsupported type %s, formatted with %%v", w.FieldName, w.FieldType)
+}
+
+/*
+Appends the query params for one field to queries, see RequestStructToquery for the supported types.
+A field of an unsupported type is stringified with fmt.Sprintf, use GetAndAppendQueriesWithWarning to be told when
+*/
 func GetAndAppendQueries(rawValue interface{}, fieldTypeString string, fieldNameString string, queries *[]string) {
+	// the only errors are *UnsupportedTypeWarning, and those fields are still appended
+	_ = GetAndAppendQueriesWithWarning(rawValue, fieldTypeString, fieldNameString, queries)
+}
+
+/* GetAndAppendQueries, returning an *UnsupportedTypeWarning if the field was stringified with fmt.Sprintf because its type is not supported */
+func GetAndAppendQueriesWithWarning(rawValue interface{}, fieldTypeString string, fieldNameString string, queries *[]string) error {
 	switch fieldTypeString {
 	case "*[]string":
 
@@ -127,54 +257,168 @@ func GetAndAppendQueries(rawValue interface{}, fieldTypeString string, fieldName
 			*queries = append(*queries, subSli...)
 		}
 
+	case "*[]uint":
+		var sli *[]uint = rawValue.(*[]uint)
+		if sli != nil && len(*sli) > 0 {
+			var subSli []string
+			for _, numb := range *sli {
+				subSli = append(subSli, fieldNameString+"[]="+strconv.FormatUint(uint64(numb), 10))
+			}
+			*queries = append(*queries, subSli...)
+		}
+
+	case "*[]uint32":
+		var sli *[]uint32 = rawValue.(*[]uint32)
+		if sli != nil && len(*sli) > 0 {
+			var subSli []string
+			for _, numb := range *sli {
+				subSli = append(subSli, fieldNameString+"[]="+strconv.FormatUint(uint64(numb), 10))
+			}
+			*queries = append(*queries, subSli...)
+		}
+
+	case "*[]uint64":
+		var sli *[]uint64 = rawValue.(*[]uint64)
+		if sli != nil && len(*sli) > 0 {
+			var subSli []string
+			for _, numb := range *sli {
+				subSli = append(subSli, fieldNameString+"[]="+strconv.FormatUint(numb, 10))
+			}
+			*queries = append(*queries, subSli...)
+		}
+
+	case "*map[string]string":
+		// one param per key, sorted so the query string is deterministic
+		var m *map[string]string = rawValue.(*map[string]string)
+		if m != nil && len(*m) > 0 {
+			for _, key := range sortedKeys(*m) {
+				*queries = append(*queries, url.QueryEscape(key)+"="+url.QueryEscape((*m)[key]))
+			}
+		}
+
+	case "*map[string][]string":
+		// key[]=value per value, as for *[]string
+		var m *map[string][]string = rawValue.(*map[string][]string)
+		if m != nil && len(*m) > 0 {
+			for _, key := range sortedKeys(*m) {
+				for _, str := range (*m)[key] {
+					*queries = append(*queries, url.QueryEscape(key)+"[]="+url.QueryEscape(str))
+				}
+			}
+		}
+
 	case "*string":
-		var qStr string
+		// nil pointers are absent params, as RequestStructToquery skips nil fields
 		var str *string = rawValue.(*string) //type assert raw Field.Interface() to *string
-		qStr += fieldNameString + "=" + *str
-		*queries = append(*queries, qStr)
+		if str != nil {
+			*queries = append(*queries, fieldNameString+"="+*str)
+		}
 
 	case "*int":
-		var qStr string
 		var numb *int = rawValue.(*int)
-		numbStr := strconv.Itoa(*numb)
-		qStr += fieldNameString + "=" + numbStr
+		if numb != nil {
+			*queries = append(*queries, fieldNameString+"="+strconv.Itoa(*numb))
+		}
 
 	case "*int32":
-		var qStr string
 		var numb *int32 = rawValue.(*int32)
-		numbStr := strconv.FormatInt(int64(*numb), 10)
-		qStr += fieldNameString + "=" + numbStr
+		if numb != nil {
+			*queries = append(*queries, fieldNameString+"="+strconv.FormatInt(int64(*numb), 10))
+		}
 
 	case "*int64":
-		var qStr string
 		var numb *int64 = rawValue.(*int64)
-		numbStr := strconv.FormatInt(*numb, 10)
-		qStr += fieldNameString + "=" + numbStr
+		if numb != nil {
+			*queries = append(*queries, fieldNameString+"="+strconv.FormatInt(*numb, 10))
+		}
 
 	case "*big.Int":
-		var qStr string
 		var numb *big.Int = rawValue.(*big.Int)
-		numbStr := numb.String()
-		qStr += fieldNameString + "=" + numbStr
+		if numb != nil {
+			*queries = append(*queries, fieldNameString+"="+numb.String())
+		}
 
 	case "*bool":
-		//do string array
-		var qStr string
-		var str *bool = rawValue.(*bool) //type assert raw Field.Interface() to *string
-		qStr += fieldNameString + "=" + strconv.FormatBool(*str)
-		*queries = append(*queries, qStr)
+		var boolean *bool = rawValue.(*bool)
+		if boolean != nil {
+			*queries = append(*queries, fieldNameString+"="+strconv.FormatBool(*boolean))
+		}
+
+	default:
+		// a nil pointer would panic in MarshalText or String with a value receiver
+		if rawValue == nil {
+			return nil
+		}
+		if val := reflect.ValueOf(rawValue); val.Kind() == reflect.Pointer && val.IsNil() {
+			return nil
+		}
+		// custom types i.e. string enums, are serialised by their own text representation
+		switch v := rawValue.(type) {
+		case encoding.TextMarshaler:
+			text, err := v.MarshalText()
+			if err == nil {
+				*queries = append(*queries, fieldNameString+"="+string(text))
+			}
+		case fmt.Stringer:
+			*queries = append(*queries, fieldNameString+"="+v.String())
+		default:
+			// last resort, format the pointed to value rather than the pointer's address
+			val := reflect.ValueOf(rawValue)
+			for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+				if val.IsNil() {
+					return nil
+				}
+				val = val.Elem()
+			}
+			*queries = append(*queries, fieldNameString+"="+fmt.Sprintf("%v", val.Interface()))
+			return &UnsupportedTypeWarning{FieldName: fieldNameString, FieldType: fieldTypeString}
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+	return keys
 }
 
 /*
--	Req struct should only have *string, *[]string, *int, *int32, *int64, *big.Int, and *bool
+-	Req struct should only have *string, *[]string, *[]uint, *[]uint32, *[]uint64, *int, *int32, *int64, *big.Int, and *bool
+-	*map[string]string and *map[string][]string fields add each key as its own url encoded param (the field name is not used), in sorted key order
+-	Pointers to types implementing encoding.TextMarshaler or fmt.Stringer are also supported, serialised via MarshalText() or String()
 -	Pointers only so we can check for absence with nil
 -	Since GET query params are always strings, the safest best is to only work with request structs onf type *string
--	Req fields should all be CamelCase, to be translated into snake-case for the queryparam keys
+-	Req fields should all be CamelCase, to be translated into snake-case for the queryparam keys, or set the key with a `query:"key"` tag
 -	req <interface{}> : The provided get request struct i.e. {"QueryParamOne": "true", "QueryParamTwo":"TSLA"}
+-	An all nil struct gives "?", use IsEmptyQueryStruct first if that should be an error
 */
 func RequestStructToquery(req interface{}) string {
+	// the only errors are *UnsupportedTypeWarning, and those fields are still appended
+	query, _ := RequestStructToqueryWithOptions(req, QueryOptions{})
+	return query
+}
+
+/*
+Options for RequestStructToqueryWithOptions
+
+  - KeyEncoder <func(fieldName string) string> : turns a Go field name into its query param key, defaults to ToSnakeCase.
+    Fields with a `query:"key"` tag use the tag instead
+*/
+type QueryOptions struct {
+	KeyEncoder func(fieldName string) string
+}
+
+/*
+RequestStructToquery with QueryOptions, for APIs that want keys other than snake-case.
+The error joins any *UnsupportedTypeWarning, the query string is complete either way
+*/
+func RequestStructToqueryWithOptions(req interface{}, opts QueryOptions) (string, error) {
 	var queries []string
+	var warnings []error
 	val := reflect.ValueOf(req)
 	typ := val.Type()
 	for i := 0; i < val.NumField(); i++ {
@@ -188,16 +432,46 @@ func RequestStructToquery(req interface{}) string {
 			fieldType := typ.Field(i)
 			fieldNameStringCamel := fieldType.Name // "SomeQueryParam", so we know how to make the ?query-param key
 
-			fieldNameStringSnake := ToSnakeCase(fieldNameStringCamel)
+			fieldNameStringKey := queryKey(fieldType, fieldNameStringCamel, opts.KeyEncoder)
 
-			GetAndAppendQueries(field.Interface(), fieldTypeString, fieldNameStringSnake, &queries)
+			if err := GetAndAppendQueriesWithWarning(field.Interface(), fieldTypeString, fieldNameStringKey, &queries); err != nil {
+				warnings = append(warnings, err)
+			}
 		}
 	}
 	qStr1 := strings.Join(queries, "&")
 	qStr0 := "?" + qStr1
 
-	return qStr0
+	return qStr0, errors.Join(warnings...)
+
+}
+
+/*
+Reports whether every pointer field of a request struct is nil, i.e. RequestStructToquery would return just "?".
+Check this first if an all nil request is an error in your API, RequestStructToquery cannot tell it apart from a mistake
+
+  - req <interface{}> : request struct or pointer to one, a nil pointer counts as empty
 
+Returns false for anything that is not a struct or pointer to one, including a nil interface
+*/
+func IsEmptyQueryStruct(req interface{}) bool {
+	val := reflect.ValueOf(req)
+	if val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return true
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Field(i)
+		if field.Kind() == reflect.Pointer && !field.IsNil() {
+			return false
+		}
+	}
+	return true
 }
 
 func createThing[T any]() T {
@@ -0,0 +1,176 @@
+package http_utils
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRequestStructToqueryUintSlices(t *testing.T) {
+	type uintReq struct {
+		Ids    *[]uint
+		Flags  *[]uint32
+		Masks  *[]uint64
+		Others *[]uint64
+	}
+	tests := []struct {
+		name string
+		req  uintReq
+		want string
+	}{
+		{"zero values", uintReq{Ids: &[]uint{0}, Flags: &[]uint32{0}, Masks: &[]uint64{0}}, "?ids[]=0&flags[]=0&masks[]=0"},
+		{"max values", uintReq{Ids: &[]uint{math.MaxUint}, Flags: &[]uint32{math.MaxUint32}, Masks: &[]uint64{math.MaxUint64}},
+			"?ids[]=" + strconv.FormatUint(math.MaxUint, 10) + "&flags[]=4294967295&masks[]=18446744073709551615"},
+		{"several values", uintReq{Masks: &[]uint64{1, 2, 3}}, "?masks[]=1&masks[]=2&masks[]=3"},
+		{"empty slice", uintReq{Others: &[]uint64{}}, "?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RequestStructToquery(tt.req); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type testColour int
+
+const (
+	testRed testColour = iota
+	testGreen
+)
+
+func (c testColour) String() string {
+	return [...]string{"red", "green"}[c]
+}
+
+type testStatus int
+
+func (s testStatus) MarshalText() ([]byte, error) {
+	if s == 1 {
+		return []byte("active"), nil
+	}
+	return []byte("inactive"), nil
+}
+
+func TestRequestStructToqueryStringerAndTextMarshaler(t *testing.T) {
+	type enumReq struct {
+		Colour *testColour
+		Status *testStatus
+	}
+	green, active := testGreen, testStatus(1)
+	if got, want := RequestStructToquery(enumReq{Colour: &green, Status: &active}), "?colour=green&status=active"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	red := testRed
+	if got, want := RequestStructToquery(enumReq{Colour: &red}), "?colour=red"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestIsEmptyQueryStruct(t *testing.T) {
+	type request struct {
+		Name  *string
+		Limit *int
+	}
+	var nilRequest *request
+	tests := []struct {
+		name string
+		req  interface{}
+		want bool
+	}{
+		{"all nil fields", request{}, true},
+		{"pointer to all nil fields", &request{}, true},
+		{"nil pointer", nilRequest, true},
+		{"a field set", &request{Limit: Ptr(10)}, false},
+		{"nil interface", nil, false},
+		{"non-struct", "name=x", false},
+		{"pointer to non-struct", Ptr(3), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsEmptyQueryStruct(tt.req); got != tt.want {
+				t.Errorf("IsEmptyQueryStruct(%#v) = %v, want %v", tt.req, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAndAppendQueriesWithWarning(t *testing.T) {
+	var queries []string
+	if err := GetAndAppendQueriesWithWarning(Ptr("x"), "*string", "name", &queries); err != nil {
+		t.Errorf("*string: unexpected warning %v", err)
+	}
+	err := GetAndAppendQueriesWithWarning(Ptr(1.5), "*float32x", "ratio", &queries)
+	var warning *UnsupportedTypeWarning
+	if !errors.As(err, &warning) || warning.FieldName != "ratio" {
+		t.Errorf("err = %v, want an *UnsupportedTypeWarning for ratio", err)
+	}
+	if len(queries) != 2 || queries[1] != "ratio=1.5" {
+		t.Errorf("queries = %v, the unsupported field should still be appended", queries)
+	}
+
+	// the original signature appends the same way without returning the warning
+	var plain []string
+	GetAndAppendQueries(Ptr(1.5), "*float32x", "ratio", &plain)
+	if len(plain) != 1 || plain[0] != "ratio=1.5" {
+		t.Errorf("GetAndAppendQueries appended %v", plain)
+	}
+}
+
+func TestRequestStructToqueryKeyEncoder(t *testing.T) {
+	type request struct {
+		SearchTerm *string
+		PageSize   *int
+		Tagged     *string `query:"t"`
+	}
+	req := request{SearchTerm: Ptr("go"), PageSize: Ptr(20), Tagged: Ptr("x")}
+	tests := []struct {
+		name    string
+		encoder func(string) string
+		want    string
+	}{
+		{"default", nil, "?search-term=go&page-size=20&t=x"},
+		{"identity keeps the field name", func(name string) string { return name }, "?SearchTerm=go&PageSize=20&t=x"},
+		{"lowercase", strings.ToLower, "?searchterm=go&pagesize=20&t=x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RequestStructToqueryWithOptions(req, QueryOptions{KeyEncoder: tt.encoder})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestStructToqueryMaps(t *testing.T) {
+	type request struct {
+		Filters *map[string]string
+		Tags    *map[string][]string
+	}
+	tests := []struct {
+		name string
+		req  request
+		want string
+	}{
+		{"nil field", request{}, "?"},
+		{"pointer to nil maps", request{Filters: Ptr(map[string]string(nil)), Tags: Ptr(map[string][]string(nil))}, "?"},
+		{"empty maps", request{Filters: &map[string]string{}, Tags: &map[string][]string{}}, "?"},
+		{"sorted keys", request{Filters: &map[string]string{"zeta": "1", "alpha": "2", "mid": "3"}}, "?alpha=2&mid=3&zeta=1"},
+		{"escaped", request{Filters: &map[string]string{"a b": "c&d"}}, "?a+b=c%26d"},
+		{"multi value", request{Tags: &map[string][]string{"b": {"2", "3"}, "a": {"1"}}}, "?a[]=1&b[]=2&b[]=3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RequestStructToquery(tt.req); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
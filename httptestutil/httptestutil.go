@@ -0,0 +1,156 @@
+/*
+Test helpers for code using http_utils, kept in their own package so the http_utils package does not import testing
+*/
+package httptestutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	http_utils "github.com/rogue-syntax/http_utils"
+)
+
+/*
+Test helper comparing two *Response, reporting each mismatch with t.Error
+
+  - StatusCode and Status are compared exactly, Status only if expected.Status is set
+
+  - Body is compared as json (ignoring key order) when both bodies are json, otherwise byte for byte
+
+  - only the headers set in expected.Header are compared, so a test can check just the headers it cares about
+*/
+func AssertResponseEqual(t testing.TB, expected, actual *http_utils.Response) {
+	t.Helper()
+	if expected == nil || actual == nil {
+		if expected != actual {
+			t.Errorf("response: expected %v, got %v", expected, actual)
+		}
+		return
+	}
+	if expected.StatusCode != actual.StatusCode {
+		t.Errorf("status code: expected %d, got %d", expected.StatusCode, actual.StatusCode)
+	}
+	if expected.Status != "" && expected.Status != actual.Status {
+		t.Errorf("status: expected %q, got %q", expected.Status, actual.Status)
+	}
+	if equal, err := http_utils.JSONEqual(expected.Body, actual.Body); err == nil {
+		if !equal {
+			t.Errorf("json body:\nexpected %s\ngot      %s", compactJSON(expected.Body), compactJSON(actual.Body))
+		}
+	} else if !bytes.Equal(expected.Body, actual.Body) {
+		t.Errorf("body:\nexpected %q\ngot      %q", expected.Body, actual.Body)
+	}
+	for name, values := range expected.Header {
+		if got := actual.Header.Values(name); !reflect.DeepEqual(values, got) {
+			t.Errorf("header %s: expected %q, got %q", name, values, got)
+		}
+	}
+}
+
+func compactJSON(data []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
+
+/* Test helper failing t unless http_utils.QueryStringsEqual(expected, actual), i.e. for checking http_utils.RequestStructToquery output */
+func AssertQueryStringEqual(t testing.TB, expected, actual string) {
+	t.Helper()
+	equal, err := http_utils.QueryStringsEqual(expected, actual)
+	if err != nil {
+		t.Errorf("query string: %v", err)
+		return
+	}
+	if !equal {
+		t.Errorf("query string:\nexpected %s\ngot      %s", expected, actual)
+	}
+}
+
+/*
+An http.RoundTripper returning canned responses, for testing code that calls HttpPostReq without starting a server, i.e.
+
+	rt := NewTestRoundTripper(map[string]*http.Response{
+		"GET https://api.example.com/users/1": {StatusCode: 200, Body: io.NopCloser(strings.NewReader(`{"id":1}`))},
+	})
+	cfg := &http_utils.ClientConfig{Transport: rt} // or &http.Client{Transport: rt}
+
+Requests with no canned response get an empty 404. Each call returns a fresh copy, so a response can be served any number of times
+*/
+type TestRoundTripper struct {
+	responses map[string]*http.Response
+	bodies    map[string][]byte
+	mu        sync.Mutex
+	calls     map[string]int
+}
+
+/* responses is keyed by "METHOD URL", response bodies are read straight away */
+func NewTestRoundTripper(responses map[string]*http.Response) *TestRoundTripper {
+	rt := &TestRoundTripper{
+		responses: responses,
+		bodies:    make(map[string][]byte, len(responses)),
+		calls:     map[string]int{},
+	}
+	for key, response := range responses {
+		if response.Body != nil {
+			body, _ := io.ReadAll(response.Body)
+			response.Body.Close()
+			rt.bodies[key] = body
+		}
+	}
+	return rt
+}
+
+func (rt *TestRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	key := req.Method + " " + req.URL.String()
+	rt.mu.Lock()
+	rt.calls[key]++
+	rt.mu.Unlock()
+
+	canned, ok := rt.responses[key]
+	if !ok {
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Status:     "404 Not Found",
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+	response := *canned
+	response.Header = canned.Header.Clone()
+	if response.Header == nil {
+		response.Header = http.Header{}
+	}
+	if response.Status == "" {
+		response.Status = strconv.Itoa(response.StatusCode) + " " + http.StatusText(response.StatusCode)
+	}
+	response.Body = io.NopCloser(bytes.NewReader(rt.bodies[key]))
+	response.ContentLength = int64(len(rt.bodies[key]))
+	response.Request = req
+	return &response, nil
+}
+
+/* Fails the test unless "method url" was requested exactly times times */
+func (rt *TestRoundTripper) AssertCalled(t testing.TB, method, url string, times int) {
+	t.Helper()
+	rt.mu.Lock()
+	got := rt.calls[method+" "+url]
+	rt.mu.Unlock()
+	if got != times {
+		t.Errorf("%s %s: expected %d calls, got %d", method, url, times, got)
+	}
+}
@@ -0,0 +1,111 @@
+package httptestutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	http_utils "github.com/rogue-syntax/http_utils"
+)
+
+/* Records Errorf calls instead of failing, so the helpers' own failures can be checked */
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertResponseEqual(t *testing.T) {
+	actual := &http_utils.Response{
+		StatusCode: 200,
+		Status:     "200 OK",
+		Header:     http.Header{"Content-Type": {"application/json"}, "X-Other": {"1"}},
+		Body:       []byte(`{"b": 2, "a": 1}`),
+	}
+	tests := []struct {
+		name     string
+		expected *http_utils.Response
+		failures int
+	}{
+		{"json key order ignored", &http_utils.Response{StatusCode: 200, Body: []byte(`{"a":1,"b":2}`)}, 0},
+		{"only expected headers checked", &http_utils.Response{StatusCode: 200, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{"a":1,"b":2}`)}, 0},
+		{"status code and body differ", &http_utils.Response{StatusCode: 404, Body: []byte(`{"a":1}`)}, 2},
+		{"header differs", &http_utils.Response{StatusCode: 200, Header: http.Header{"X-Other": {"2"}}, Body: []byte(`{"a":1,"b":2}`)}, 1},
+		{"nil expected", nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingTB{}
+			AssertResponseEqual(rec, tt.expected, actual)
+			if len(rec.errors) != tt.failures {
+				t.Errorf("got %d failures, want %d: %q", len(rec.errors), tt.failures, rec.errors)
+			}
+		})
+	}
+}
+
+func TestAssertResponseEqualNonJSONBody(t *testing.T) {
+	rec := &recordingTB{}
+	AssertResponseEqual(rec, &http_utils.Response{StatusCode: 200, Body: []byte("hello")}, &http_utils.Response{StatusCode: 200, Body: []byte("hello!")})
+	if len(rec.errors) != 1 {
+		t.Errorf("got %q, want one body failure", rec.errors)
+	}
+}
+
+func TestTestRoundTripperWithClientConfig(t *testing.T) {
+	rt := NewTestRoundTripper(map[string]*http.Response{
+		"GET https://api.example.com/users/1": {StatusCode: 200, Header: http.Header{"Content-Type": {"application/json"}}, Body: io.NopCloser(strings.NewReader(`{"id":1}`))},
+	})
+	cfg := &http_utils.ClientConfig{Transport: rt}
+	for i := 0; i < 2; i++ {
+		response, err := http_utils.HttpPostReqContext(context.Background(), cfg, http.MethodGet, nil, "https://api.example.com/users/1", nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		AssertResponseEqual(t, &http_utils.Response{
+			StatusCode: 200,
+			Status:     "200 OK",
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       []byte(`{"id":1}`),
+		}, response)
+	}
+	rt.AssertCalled(t, http.MethodGet, "https://api.example.com/users/1", 2)
+
+	response, err := http_utils.HttpPostReqContext(context.Background(), cfg, http.MethodGet, nil, "https://api.example.com/users/2", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("uncanned request: status %d, want 404", response.StatusCode)
+	}
+}
+
+func TestTestRoundTripperAssertCalledFails(t *testing.T) {
+	rt := NewTestRoundTripper(nil)
+	rec := &recordingTB{}
+	rt.AssertCalled(rec, http.MethodGet, "https://api.example.com/", 1)
+	if len(rec.errors) != 1 {
+		t.Errorf("got %q, want one failure", rec.errors)
+	}
+}
+
+func TestAssertQueryStringEqual(t *testing.T) {
+	rec := &recordingTB{}
+	AssertQueryStringEqual(rec, "?b=2&a=1", "a=1&b=2")
+	if len(rec.errors) != 0 {
+		t.Errorf("same params in another order: %q", rec.errors)
+	}
+	AssertQueryStringEqual(rec, "a=1", "a=2")
+	AssertQueryStringEqual(rec, "a=%zz", "a=1")
+	if len(rec.errors) != 2 {
+		t.Errorf("got %q, want a mismatch and a parse failure", rec.errors)
+	}
+}
@@ -0,0 +1,79 @@
+package http_utils
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+/* Returned by VerifyBodyIntegrity when a body does not hash to its header's value, Expected and Actual are base64 */
+type ChecksumMismatchError struct {
+	Header    string // "Digest" or "Content-MD5"
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%s %s mismatch: expected %s, got %s", e.Header, e.Algorithm, e.Expected, e.Actual)
+}
+
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha-512": sha512.New,
+	"sha-256": sha256.New,
+	"sha":     sha1.New,
+	"md5":     md5.New,
+}
+
+/*
+Checks body against the Digest (RFC 3230, i.e. "SHA-256=X48E9q...") and Content-MD5 headers, returning a *ChecksumMismatchError on the first mismatch.
+Every supported algorithm in Digest (SHA-512, SHA-256, SHA, MD5) is checked, others are skipped.
+nil when neither header is present, so it is safe to run on every response
+*/
+func VerifyBodyIntegrity(body []byte, headers http.Header) error {
+	for _, digest := range headers.Values("Digest") {
+		for _, instance := range strings.Split(digest, ",") {
+			algorithm, expected, ok := strings.Cut(strings.TrimSpace(instance), "=")
+			if !ok {
+				return fmt.Errorf("malformed Digest %q", instance)
+			}
+			newHash, supported := digestAlgorithms[strings.ToLower(algorithm)]
+			if !supported {
+				continue
+			}
+			if err := checkBodyHash(body, newHash, "Digest", algorithm, expected); err != nil {
+				return err
+			}
+		}
+	}
+	if expected := headers.Get("Content-MD5"); expected != "" {
+		return checkBodyHash(body, md5.New, "Content-MD5", "MD5", expected)
+	}
+	return nil
+}
+
+func checkBodyHash(body []byte, newHash func() hash.Hash, header, algorithm, expected string) error {
+	h := newHash()
+	h.Write(body)
+	actual := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	if actual != strings.TrimSpace(expected) {
+		return &ChecksumMismatchError{Header: header, Algorithm: algorithm, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+/* A ResponseInterceptor failing responses whose body does not match their Digest or Content-MD5 header, see VerifyBodyIntegrity */
+func VerifyIntegrityInterceptor() ResponseInterceptor {
+	return func(resp *Response) (*Response, error) {
+		if err := VerifyBodyIntegrity(resp.Body, resp.Header); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+}
@@ -0,0 +1,32 @@
+package http_utils
+
+import "context"
+
+/*
+Runs on every response of HttpPostReqWithInterceptors, returning the response to pass on (the same one, a modified copy or a new one)
+or an error to stop the chain, i.e. for logging, metrics or checking the status
+*/
+type ResponseInterceptor func(resp *Response) (*Response, error)
+
+/*
+HttpPostReqContext with interceptors run in order on the response, see HttpPostReq for the other arguments.
+Interceptors are not run when the request itself fails
+*/
+func HttpPostReqWithInterceptors(ctx context.Context, cfg *ClientConfig, method string, payload interface{}, url string, reqHeaders []ReqHeader, addHeaders []ReqHeader, interceptors []ResponseInterceptor) (*Response, error) {
+	response, err := HttpPostReqContext(ctx, cfg, method, payload, url, reqHeaders, addHeaders)
+	if err != nil {
+		return nil, err
+	}
+	return runInterceptors(response, interceptors)
+}
+
+func runInterceptors(response *Response, interceptors []ResponseInterceptor) (*Response, error) {
+	var err error
+	for _, interceptor := range interceptors {
+		response, err = interceptor(response)
+		if err != nil {
+			return response, err
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,67 @@
+package http_utils
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+	"time"
+)
+
+/* A uniformly random duration in [0, d], d <= 0 returns 0 */
+func FullJitter(d time.Duration) time.Duration {
+	return randomDuration(0, d)
+}
+
+/* A uniformly random duration in [d/2, d], keeps at least half the wait while still spreading retries out */
+func EqualJitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + randomDuration(0, d-d/2)
+}
+
+/*
+The AWS decorrelated jitter algorithm, min(max, random between base and prev*3).
+Pass base as prev for the first retry and the previous result after that
+See https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+*/
+func DecorrelatedJitter(prev, base, max time.Duration) time.Duration {
+	upper := prev * 3
+	if prev > max/3 {
+		upper = max // also avoids overflowing prev*3
+	}
+	if upper < base {
+		upper = base
+	}
+	d := randomDuration(base, upper)
+	if d > max {
+		return max
+	}
+	return d
+}
+
+/* Uniformly random in [min, max] using crypto/rand, min when max <= min */
+func randomDuration(min, max time.Duration) time.Duration {
+	if max <= min {
+		if min < 0 {
+			return 0
+		}
+		return min
+	}
+	n, err := rand.Int(rand.Reader, new(big.Int).Add(big.NewInt(int64(max-min)), big.NewInt(1)))
+	if err != nil {
+		// crypto/rand failing means the system is broken, fall back to no jitter
+		return max
+	}
+	return min + time.Duration(n.Int64())
+}
+
+/* Uniformly random in [0, 1) using crypto/rand, in the same way as math/rand's Float64 */
+func randomFloat64() float64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// as in randomDuration, crypto/rand failing means the system is broken
+		return 0
+	}
+	return float64(binary.BigEndian.Uint64(b[:])>>11) / (1 << 53)
+}
@@ -0,0 +1,81 @@
+package http_utils
+
+import (
+	"testing"
+	"time"
+)
+
+const jitterSamples = 1000
+
+func TestFullJitterBounds(t *testing.T) {
+	d := 100 * time.Millisecond
+	for i := 0; i < jitterSamples; i++ {
+		if got := FullJitter(d); got < 0 || got > d {
+			t.Fatalf("FullJitter(%s) = %s, want [0, %s]", d, got, d)
+		}
+	}
+	if got := FullJitter(0); got != 0 {
+		t.Errorf("FullJitter(0) = %s", got)
+	}
+	if got := FullJitter(-time.Second); got != 0 {
+		t.Errorf("FullJitter(-1s) = %s", got)
+	}
+}
+
+func TestEqualJitterBounds(t *testing.T) {
+	for _, d := range []time.Duration{1, 3, 100 * time.Millisecond} {
+		for i := 0; i < jitterSamples; i++ {
+			if got := EqualJitter(d); got < d/2 || got > d {
+				t.Fatalf("EqualJitter(%s) = %s, want [%s, %s]", d, got, d/2, d)
+			}
+		}
+	}
+	if got := EqualJitter(-time.Second); got != 0 {
+		t.Errorf("EqualJitter(-1s) = %s", got)
+	}
+}
+
+func TestDecorrelatedJitterBounds(t *testing.T) {
+	base, max := 10*time.Millisecond, time.Second
+	prev := base
+	for i := 0; i < jitterSamples; i++ {
+		upper := prev * 3
+		if upper > max {
+			upper = max
+		}
+		got := DecorrelatedJitter(prev, base, max)
+		if got < base || got > upper {
+			t.Fatalf("DecorrelatedJitter(%s, %s, %s) = %s, want [%s, %s]", prev, base, max, got, base, upper)
+		}
+		prev = got
+	}
+}
+
+func TestDecorrelatedJitterLargePrevDoesNotOverflow(t *testing.T) {
+	max := time.Minute
+	prev := time.Duration(1 << 62) // prev*3 overflows int64
+	for i := 0; i < jitterSamples; i++ {
+		if got := DecorrelatedJitter(prev, time.Second, max); got < time.Second || got > max {
+			t.Fatalf("DecorrelatedJitter = %s, want [1s, %s]", got, max)
+		}
+	}
+}
+
+func TestJitterSpreads(t *testing.T) {
+	d := time.Second
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		seen[FullJitter(d)] = true
+	}
+	if len(seen) < 50 {
+		t.Errorf("100 FullJitter samples gave only %d distinct values", len(seen))
+	}
+}
+
+func TestRandomFloat64Bounds(t *testing.T) {
+	for i := 0; i < jitterSamples; i++ {
+		if f := randomFloat64(); f < 0 || f >= 1 {
+			t.Fatalf("randomFloat64() = %v, want [0, 1)", f)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package http_utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/* Returned by JSONPath when a path segment does not exist */
+type PathNotFoundError struct {
+	Path    string
+	Segment string
+}
+
+func (e *PathNotFoundError) Error() string {
+	return fmt.Sprintf("json path %q: segment %q not found", e.Path, e.Segment)
+}
+
+/*
+Returns the raw json at a dot-notation path, for decoding a single nested field without a full struct i.e.
+
+	raw, err := JSONPath(body, "data.user.id")
+	raw, err := JSONPath(body, "data.items.0.name") // numeric segments index arrays
+
+An empty path returns data as is. Returns a *PathNotFoundError when any segment is missing
+*/
+func JSONPath(data []byte, path string) ([]byte, error) {
+	current := json.RawMessage(data)
+	if path == "" {
+		return current, nil
+	}
+	for _, segment := range strings.Split(path, ".") {
+		trimmed := strings.TrimLeft(string(current), " \t\r\n")
+		switch {
+		case strings.HasPrefix(trimmed, "{"):
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(current, &obj); err != nil {
+				return nil, err
+			}
+			next, ok := obj[segment]
+			if !ok {
+				return nil, &PathNotFoundError{Path: path, Segment: segment}
+			}
+			current = next
+		case strings.HasPrefix(trimmed, "["):
+			var arr []json.RawMessage
+			if err := json.Unmarshal(current, &arr); err != nil {
+				return nil, err
+			}
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(arr) {
+				return nil, &PathNotFoundError{Path: path, Segment: segment}
+			}
+			current = arr[i]
+		default:
+			if !json.Valid(current) {
+				return nil, fmt.Errorf("json path %q: invalid json", path)
+			}
+			return nil, &PathNotFoundError{Path: path, Segment: segment}
+		}
+	}
+	return current, nil
+}
+
+/*
+Returns a json object with only the requested fields of data, keeping their raw values, i.e. for caching part of a large response
+
+	SelectFields(body, "id", "user.name") // {"id":1,"user":{"name":"x"}}
+
+Fields use JSONPath dot-notation, missing fields are left out. Selecting both a field and something inside it keeps the whole field.
+Array indexes in a path become object keys in the result, i.e. "items.0.id" gives {"items":{"0":{"id":1}}}
+*/
+func SelectFields(data []byte, fields ...string) ([]byte, error) {
+	selected := map[string]interface{}{}
+	for _, field := range fields {
+		raw, err := JSONPath(data, field)
+		if err != nil {
+			var notFound *PathNotFoundError
+			if errors.As(err, &notFound) {
+				continue
+			}
+			return nil, err
+		}
+		node := selected
+		segments := strings.Split(field, ".")
+		for _, segment := range segments[:len(segments)-1] {
+			child, ok := node[segment].(map[string]interface{})
+			if !ok {
+				if _, whole := node[segment]; whole {
+					node = nil
+					break
+				}
+				child = map[string]interface{}{}
+				node[segment] = child
+			}
+			node = child
+		}
+		if node != nil {
+			node[segments[len(segments)-1]] = json.RawMessage(raw)
+		}
+	}
+	return Marshal(selected)
+}
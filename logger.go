@@ -0,0 +1,20 @@
+package http_utils
+
+import (
+	"log"
+	"sync/atomic"
+)
+
+var packageLogger atomic.Pointer[log.Logger]
+
+/* Sets the logger used by the middleware in this package, nil restores the default, log.Default() */
+func SetLogger(l *log.Logger) {
+	packageLogger.Store(l)
+}
+
+func getLogger() *log.Logger {
+	if l := packageLogger.Load(); l != nil {
+		return l
+	}
+	return log.Default()
+}
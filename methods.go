@@ -0,0 +1,28 @@
+package http_utils
+
+import (
+	"fmt"
+	"net/http"
+)
+
+/* Request methods for the method argument of HttpPostReq */
+const (
+	MethodGet     = http.MethodGet
+	MethodPost    = http.MethodPost
+	MethodPut     = http.MethodPut
+	MethodPatch   = http.MethodPatch
+	MethodDelete  = http.MethodDelete
+	MethodHead    = http.MethodHead
+	MethodOptions = http.MethodOptions
+	MethodConnect = http.MethodConnect
+	MethodTrace   = http.MethodTrace
+)
+
+/* Returns an error if m is not one of the standard request methods above, the check is case-sensitive as methods are */
+func ValidateMethod(m string) error {
+	switch m {
+	case MethodGet, MethodPost, MethodPut, MethodPatch, MethodDelete, MethodHead, MethodOptions, MethodConnect, MethodTrace:
+		return nil
+	}
+	return fmt.Errorf("unknown http method %q", m)
+}
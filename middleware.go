@@ -0,0 +1,215 @@
+package http_utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+Middleware that responds 415 Unsupported Media Type unless the request Content-Type is one of allowed.
+Only the media type is compared, so "application/json" allows "application/json; charset=utf-8"
+*/
+func ValidateContentType(allowed ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err == nil {
+				for _, a := range allowed {
+					if strings.EqualFold(mediaType, a) {
+						next.ServeHTTP(w, r)
+						return
+					}
+				}
+			}
+			http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		})
+	}
+}
+
+/*
+Middleware that responds 405 Method Not Allowed, with an Allow header listing allowed, unless the request method is one of allowed.
+Named ValidateRequestMethod as ValidateMethod already validates a single method string
+*/
+func ValidateRequestMethod(allowed ...string) func(http.Handler) http.Handler {
+	allow := strings.Join(allowed, ", ")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, a := range allowed {
+				if r.Method == a {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			w.Header().Set("Allow", allow)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		})
+	}
+}
+
+/*
+Middleware that limits request bodies to maxBytes, answering larger ones with a 413 Request Entity Too Large and a json error body.
+A declared Content-Length over the limit is rejected up front and a body within it is streamed to next through an
+http.MaxBytesReader. A body of unknown length (chunked) is read up to maxBytes+1 first, so an oversize one is rejected
+before next runs, and one within the limit is handed to next from memory
+*/
+func RequestSizeLimiter(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				writeBodyTooLarge(w, maxBytes)
+				return
+			}
+			if r.Body != nil && r.Body != http.NoBody {
+				if r.ContentLength >= 0 {
+					r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+				} else {
+					body, err := io.ReadAll(io.LimitReader(r.Body, maxBytes+1))
+					if err != nil {
+						WriteJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "reading request body failed"})
+						return
+					}
+					if int64(len(body)) > maxBytes {
+						writeBodyTooLarge(w, maxBytes)
+						return
+					}
+					r.Body.Close()
+					r.Body = io.NopCloser(bytes.NewReader(body))
+					r.ContentLength = int64(len(body))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeBodyTooLarge(w http.ResponseWriter, maxBytes int64) {
+	WriteJSON(w, http.StatusRequestEntityTooLarge, map[string]interface{}{
+		"error":     "request body too large",
+		"max_bytes": maxBytes,
+	})
+}
+
+/* Whether err came from reading past the limit of an http.MaxBytesReader, i.e. one set by RequestSizeLimiter */
+func IsMaxBytesError(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+/*
+Middleware answering requests that take longer than timeout with a 503 and timeoutBody as json, written by WriteJSON.
+Works like http.TimeoutHandler: next gets a request context with the deadline and its response is buffered, so it is
+sent whole or not at all, and its writes after the timeout fail with http.ErrHandlerTimeout.
+Panics if timeoutBody cannot be marshaled, as that is a programming error
+*/
+func TimeoutHandler(timeout time.Duration, timeoutBody interface{}) func(http.Handler) http.Handler {
+	if _, err := Marshal(timeoutBody); err != nil {
+		panic(err)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			tw := &timeoutWriter{header: make(http.Header)}
+			done := make(chan struct{})
+			panicked := make(chan interface{}, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+				}()
+				next.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
+			}()
+			select {
+			case p := <-panicked:
+				panic(p)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				for name, values := range tw.header {
+					w.Header()[name] = values
+				}
+				if tw.code == 0 {
+					tw.code = http.StatusOK
+				}
+				w.WriteHeader(tw.code)
+				w.Write(tw.body.Bytes())
+			case <-ctx.Done():
+				// deciding and writing under the lock, so next cannot write between the two
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+				WriteJSON(w, http.StatusServiceUnavailable, timeoutBody)
+			}
+		})
+	}
+}
+
+/* Buffers next's response for TimeoutHandler, mu guards everything as next runs on its own goroutine */
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	code     int // 0 until WriteHeader or Write
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	return tw.body.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(statusCode int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.code != 0 {
+		return
+	}
+	tw.code = statusCode
+}
+
+/*
+Middleware capping requests handled at once at maxConcurrent (less than 1 for 1). Requests over the limit get a 503 with
+Retry-After: 1 and a json error straight away rather than queuing, so clients back off and retry
+*/
+func ConcurrencyLimiter(maxConcurrent int) func(http.Handler) http.Handler {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	sem := make(chan struct{}, maxConcurrent)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case sem <- struct{}{}:
+			default:
+				w.Header().Set("Retry-After", "1")
+				WriteJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+					"error":          "too many concurrent requests",
+					"max_concurrent": maxConcurrent,
+				})
+				return
+			}
+			defer func() { <-sem }()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package http_utils
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+/* A handler echoing the body length, failing the request if the body cannot be read */
+var readBodyHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Write([]byte(strings.Repeat("x", len(body))))
+})
+
+/* A request with a body of unknown length, as for a chunked upload */
+func unknownLengthRequest(body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader(body)))
+	r.ContentLength = -1
+	return r
+}
+
+func assertBodyTooLarge(t *testing.T, w *httptest.ResponseRecorder, maxBytes int64) {
+	t.Helper()
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status %d, want 413", w.Code)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["error"] != "request body too large" || body["max_bytes"] != float64(maxBytes) {
+		t.Errorf("body %q, err %v", w.Body.String(), err)
+	}
+}
+
+func TestRequestSizeLimiter(t *testing.T) {
+	const maxBytes = 16
+	handler := RequestSizeLimiter(maxBytes)(readBodyHandler)
+
+	t.Run("exactly maxBytes is accepted", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("a", maxBytes))))
+		if w.Code != http.StatusOK || w.Body.Len() != maxBytes {
+			t.Errorf("status %d, handler read %d bytes", w.Code, w.Body.Len())
+		}
+	})
+
+	t.Run("maxBytes+1 is rejected from Content-Length", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("a", maxBytes+1))))
+		assertBodyTooLarge(t, w, maxBytes)
+	})
+
+	t.Run("exactly maxBytes without Content-Length is accepted", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, unknownLengthRequest(strings.Repeat("a", maxBytes)))
+		if w.Code != http.StatusOK || w.Body.Len() != maxBytes {
+			t.Errorf("status %d, handler read %d bytes", w.Code, w.Body.Len())
+		}
+	})
+
+	t.Run("maxBytes+1 without Content-Length is rejected before the handler", func(t *testing.T) {
+		called := false
+		handler := RequestSizeLimiter(maxBytes)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, unknownLengthRequest(strings.Repeat("a", maxBytes+1)))
+		assertBodyTooLarge(t, w, maxBytes)
+		if called {
+			t.Error("handler ran for an oversize body")
+		}
+	})
+}
+
+func TestTimeoutHandlerWritesJSONOnTimeout(t *testing.T) {
+	writeErr := make(chan error, 1)
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		time.Sleep(10 * time.Millisecond) // let the timeout response go first
+		_, err := w.Write([]byte("too late"))
+		writeErr <- err
+	})
+	handler := TimeoutHandler(20*time.Millisecond, map[string]string{"error": "timed out"})(slow)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want 503", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := strings.TrimSpace(w.Body.String()); got != `{"error":"timed out"}` {
+		t.Errorf("body %q", got)
+	}
+	if err := <-writeErr; err != http.ErrHandlerTimeout {
+		t.Errorf("late write err = %v, want http.ErrHandlerTimeout", err)
+	}
+}
+
+func TestTimeoutHandlerPassesFastResponses(t *testing.T) {
+	fast := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", "fast")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("done"))
+	})
+	w := httptest.NewRecorder()
+	TimeoutHandler(time.Second, "timed out")(fast).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusCreated || w.Header().Get("X-Handler") != "fast" || w.Body.String() != "done" {
+		t.Errorf("got %d %v %q", w.Code, w.Header(), w.Body.String())
+	}
+}
+
+func TestTimeoutHandlerRepanics(t *testing.T) {
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("recovered %v, want boom", p)
+		}
+	}()
+	TimeoutHandler(time.Second, "timed out")(panicking).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestTimeoutHandlerPanicsOnUnmarshalableBody(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	TimeoutHandler(time.Second, make(chan int))
+}
@@ -0,0 +1,97 @@
+package http_utils
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+/* One part of a multipart response */
+type MultipartPart struct {
+	Headers http.Header
+	Body    []byte
+}
+
+/*
+Splits a multipart response body (i.e. multipart/mixed from a batch API) into its parts
+
+  - contentType <string> : the response Content-Type, must be multipart/* with a boundary parameter
+*/
+func ReadMultipartResponse(contentType string, body []byte) ([]MultipartPart, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, errors.New("not a multipart content type: " + mediaType)
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, errors.New("multipart content type has no boundary")
+	}
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var parts []MultipartPart
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		partBody, err := io.ReadAll(part)
+		part.Close()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, MultipartPart{Headers: http.Header(part.Header), Body: partBody})
+	}
+}
+
+/* Streams a multipart response from a handler, the counterpart of ReadMultipartResponse, see NewMultipartResponseWriter */
+type MultipartResponseWriter struct {
+	writer *multipart.Writer
+}
+
+/*
+Starts a multipart response on w with a random boundary, returning the writer and the boundary
+
+  - contentType <string> : the multipart media type, "" for multipart/mixed, anything else must start with multipart/
+
+The Content-Type header is set with the boundary, headers are sent on the first WritePart, call w.WriteHeader first for a status other than 200
+*/
+func NewMultipartResponseWriter(w http.ResponseWriter, contentType string) (*MultipartResponseWriter, string, error) {
+	if contentType == "" {
+		contentType = "multipart/mixed"
+	}
+	if !strings.HasPrefix(contentType, "multipart/") {
+		return nil, "", errors.New("not a multipart content type: " + contentType)
+	}
+	writer := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", mime.FormatMediaType(contentType, map[string]string{"boundary": writer.Boundary()}))
+	return &MultipartResponseWriter{writer: writer}, writer.Boundary(), nil
+}
+
+/* Writes one part with headers (i.e. a Content-Type for the part, nil for none) and body */
+func (m *MultipartResponseWriter) WritePart(headers http.Header, body []byte) error {
+	partHeaders := make(textproto.MIMEHeader, len(headers))
+	for name, values := range headers {
+		partHeaders[name] = values
+	}
+	part, err := m.writer.CreatePart(partHeaders)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(body)
+	return err
+}
+
+/* Writes the closing boundary, the response is incomplete without it */
+func (m *MultipartResponseWriter) Close() error {
+	return m.writer.Close()
+}
@@ -0,0 +1,80 @@
+package http_utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+/* A batch response in the shape the Google Batch API returns, with CRLF line endings */
+var multipartFixture = strings.ReplaceAll(`--batch_abc
+Content-Type: application/http
+Content-ID: <response-1>
+
+HTTP/1.1 200 OK
+Content-Type: application/json
+
+{"id":1}
+--batch_abc
+Content-Type: application/http
+Content-ID: <response-2>
+
+HTTP/1.1 404 Not Found
+
+--batch_abc--
+`, "\n", "\r\n")
+
+func TestReadMultipartResponse(t *testing.T) {
+	parts, err := ReadMultipartResponse("multipart/mixed; boundary=batch_abc", []byte(multipartFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("got %d parts, want 2", len(parts))
+	}
+	if got := parts[0].Headers.Get("Content-ID"); got != "<response-1>" {
+		t.Errorf("part 0 Content-ID = %q", got)
+	}
+	if want := "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n{\"id\":1}"; string(parts[0].Body) != want {
+		t.Errorf("part 0 body = %q, want %q", parts[0].Body, want)
+	}
+	if got := parts[1].Headers.Get("Content-ID"); got != "<response-2>" {
+		t.Errorf("part 1 Content-ID = %q", got)
+	}
+	if want := "HTTP/1.1 404 Not Found\r\n"; string(parts[1].Body) != want {
+		t.Errorf("part 1 body = %q, want %q", parts[1].Body, want)
+	}
+}
+
+func TestReadMultipartResponseBadContentType(t *testing.T) {
+	for _, contentType := range []string{"application/json", "multipart/mixed", "multipart/mixed; boundary="} {
+		if _, err := ReadMultipartResponse(contentType, []byte(multipartFixture)); err == nil {
+			t.Errorf("%q: expected an error", contentType)
+		}
+	}
+}
+
+func TestMultipartResponseWriterRoundTrip(t *testing.T) {
+	w := httptest.NewRecorder()
+	mw, _, err := NewMultipartResponseWriter(w, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mw.WritePart(http.Header{"Content-Type": {"application/json"}}, []byte(`{"a":1}`))
+	mw.WritePart(nil, []byte("plain"))
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	parts, err := ReadMultipartResponse(w.Header().Get("Content-Type"), w.Body.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 2 || string(parts[0].Body) != `{"a":1}` || string(parts[1].Body) != "plain" {
+		t.Fatalf("got %+v", parts)
+	}
+	if got := parts[0].Headers.Get("Content-Type"); got != "application/json" {
+		t.Errorf("part 0 Content-Type = %q", got)
+	}
+}
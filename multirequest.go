@@ -0,0 +1,54 @@
+package http_utils
+
+import (
+	"context"
+	"fmt"
+)
+
+/* Responses of a MultiRequest by step name */
+type Results map[string]*Response
+
+type multiRequestStep struct {
+	name string
+	fn   func(ctx context.Context, prev Results) (*Response, error)
+}
+
+/*
+A fixed sequence of requests where each step can use the responses of the steps before it, i.e.
+
+	results, err := (&MultiRequest{}).
+		Add("auth", login).
+		Add("fetch", func(ctx context.Context, prev Results) (*Response, error) {
+			token, _ := JSONPath(prev["auth"].Body, "token")
+			...
+		}).
+		Run(ctx)
+*/
+type MultiRequest struct {
+	steps []multiRequestStep
+}
+
+/* Appends a step, name is its key in Results */
+func (m *MultiRequest) Add(name string, fn func(ctx context.Context, prev Results) (*Response, error)) *MultiRequest {
+	m.steps = append(m.steps, multiRequestStep{name: name, fn: fn})
+	return m
+}
+
+/*
+Runs the steps in order, stopping at the first error or when ctx is done.
+On error the Results of the steps that succeeded are returned with it
+*/
+func (m *MultiRequest) Run(ctx context.Context) (Results, error) {
+	results := make(Results, len(m.steps))
+	for _, step := range m.steps {
+		if err := ctx.Err(); err != nil {
+			return results, fmt.Errorf("%s: %w", step.name, err)
+		}
+		response, err := step.fn(ctx, results)
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", step.name, err)
+		}
+		results[step.name] = response
+	}
+	return results, nil
+}
@@ -0,0 +1,202 @@
+package http_utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+Settings for the OAuth 2.0 client credentials grant
+
+  - TokenURL <string> : the authorisation server's token endpoint
+
+  - ClientID, ClientSecret <string> : sent with HTTP basic auth
+
+  - Scopes <[]string> : requested scopes, left out of the request when empty
+
+  - ExpiryMargin <time.Duration> : how long before expires_in a token is refreshed, 0 for 30 seconds.
+    Capped at half of expires_in, so a short lived token is still used for a while
+*/
+type OAuthConfig struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	ExpiryMargin time.Duration
+}
+
+/*
+Fetches client credentials tokens and caches them until shortly before they expire. Safe for concurrent use,
+callers needing a token while one is being fetched wait for that fetch rather than starting their own
+*/
+type OAuthClient struct {
+	Config     OAuthConfig
+	HTTPClient *http.Client // used for token requests, nil for http.DefaultClient
+
+	mu       sync.Mutex
+	token    string
+	expiry   time.Time   // zero when the server gave no expires_in, the token is then kept until Invalidate
+	inflight *oauthFetch // the token request in progress, nil when there is none
+}
+
+/* Limit for one token request, it runs apart from the context of the Token call that started it */
+const oauthFetchTimeout = 30 * time.Second
+
+/* One token request shared by every Token call made while it runs, token and err are set before done is closed */
+type oauthFetch struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+/* The token endpoint responded with an error, Body holds the raw response i.e. {"error":"invalid_client"} */
+type OAuthTokenError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *OAuthTokenError) Error() string {
+	return fmt.Sprintf("oauth token request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+type oauthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+/*
+Returns the cached access token, fetching a new one when there is none or it is about to expire.
+Returns ctx.Err() if ctx is done first, the fetch carries on for the other callers and the cache
+*/
+func (c *OAuthClient) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	if c.token != "" && (c.expiry.IsZero() || time.Now().Before(c.expiry)) {
+		token := c.token
+		c.mu.Unlock()
+		return token, nil
+	}
+	fetch := c.inflight
+	if fetch == nil {
+		fetch = &oauthFetch{done: make(chan struct{})}
+		c.inflight = fetch
+		go c.runFetch(ctx, fetch)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+		return fetch.token, fetch.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (c *OAuthClient) runFetch(ctx context.Context, fetch *oauthFetch) {
+	// keeps ctx's values but not its cancellation, other callers may be waiting on this fetch
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), oauthFetchTimeout)
+	defer cancel()
+	token, expiresIn, err := c.fetchToken(ctx)
+
+	c.mu.Lock()
+	c.inflight = nil
+	if err == nil {
+		c.token = token
+		c.expiry = time.Time{}
+		if expiresIn > 0 {
+			c.expiry = time.Now().Add(expiresIn - c.expiryMargin(expiresIn))
+		}
+	}
+	c.mu.Unlock()
+	fetch.token, fetch.err = token, err
+	close(fetch.done)
+}
+
+/* Config.ExpiryMargin or 30s, at most half of expiresIn so the token is not already expired when cached */
+func (c *OAuthClient) expiryMargin(expiresIn time.Duration) time.Duration {
+	margin := c.Config.ExpiryMargin
+	if margin == 0 {
+		margin = 30 * time.Second
+	}
+	if margin > expiresIn/2 {
+		margin = expiresIn / 2
+	}
+	return margin
+}
+
+/* Drops the cached token so the next Token call fetches a new one, i.e. after the API rejects it with a 401 */
+func (c *OAuthClient) Invalidate() {
+	c.mu.Lock()
+	c.token = ""
+	c.mu.Unlock()
+}
+
+func (c *OAuthClient) fetchToken(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.Config.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Config.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(c.Config.ClientID), url.QueryEscape(c.Config.ClientSecret))
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, &OAuthTokenError{StatusCode: resp.StatusCode, Body: body}
+	}
+	var token oauthTokenResponse
+	if err := Unmarshal(body, &token); err != nil {
+		return "", 0, err
+	}
+	if token.AccessToken == "" {
+		return "", 0, errors.New("oauth token response has no access_token")
+	}
+	return token.AccessToken, time.Duration(token.ExpiresIn) * time.Second, nil
+}
+
+/*
+An http.RoundTripper adding a client credentials Bearer token from cfg to every request, inner nil for http.DefaultTransport.
+Tokens are fetched through inner and refreshed once expired
+*/
+func NewOAuthTransport(inner http.RoundTripper, cfg OAuthConfig) http.RoundTripper {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+	client := &OAuthClient{Config: cfg, HTTPClient: &http.Client{Transport: inner}}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		token, err := client.Token(req.Context())
+		if err != nil {
+			// a RoundTripper must close the body even when it fails
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+		authed := req.Clone(req.Context())
+		authed.Header.Set("Authorization", "Bearer "+token)
+		return inner.RoundTrip(authed)
+	})
+}
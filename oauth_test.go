@@ -0,0 +1,164 @@
+package http_utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/* A token endpoint counting requests, handing out "token-N" with expiresIn seconds, release gates each response when set */
+func tokenServer(t *testing.T, expiresIn int, release chan struct{}) (*httptest.Server, *atomic.Int32) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := fetches.Add(1)
+		if user, pass, _ := r.BasicAuth(); user != "id" || pass != "secret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		if release != nil {
+			<-release
+		}
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(server.Close)
+	return server, &fetches
+}
+
+func TestOAuthClientCachesToken(t *testing.T) {
+	server, fetches := tokenServer(t, 3600, nil)
+	client := &OAuthClient{Config: OAuthConfig{TokenURL: server.URL, ClientID: "id", ClientSecret: "secret"}}
+	for i := 0; i < 3; i++ {
+		token, err := client.Token(context.Background())
+		if err != nil || token != "token-1" {
+			t.Fatalf("Token() = %q, %v", token, err)
+		}
+	}
+	client.Invalidate()
+	if token, _ := client.Token(context.Background()); token != "token-2" {
+		t.Errorf("after Invalidate got %q, want token-2", token)
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("%d fetches, want 2", got)
+	}
+}
+
+func TestOAuthClientShortExpiryUnderMargin(t *testing.T) {
+	// expires_in 10s is under the 30s default margin, the token must still be cached
+	server, fetches := tokenServer(t, 10, nil)
+	client := &OAuthClient{Config: OAuthConfig{TokenURL: server.URL, ClientID: "id", ClientSecret: "secret"}}
+	for i := 0; i < 3; i++ {
+		if _, err := client.Token(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("%d fetches, want 1", got)
+	}
+}
+
+func TestOAuthClientExpiryMargin(t *testing.T) {
+	client := &OAuthClient{}
+	if got := client.expiryMargin(time.Hour); got != 30*time.Second {
+		t.Errorf("default margin = %s", got)
+	}
+	if got := client.expiryMargin(10 * time.Second); got != 5*time.Second {
+		t.Errorf("margin for 10s = %s, want half", got)
+	}
+	client.Config.ExpiryMargin = time.Minute
+	if got := client.expiryMargin(time.Hour); got != time.Minute {
+		t.Errorf("configured margin = %s", got)
+	}
+}
+
+func TestOAuthClientConcurrentCallersShareOneFetch(t *testing.T) {
+	release := make(chan struct{})
+	server, fetches := tokenServer(t, 3600, release)
+	client := &OAuthClient{Config: OAuthConfig{TokenURL: server.URL, ClientID: "id", ClientSecret: "secret"}}
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = client.Token(context.Background())
+		}(i)
+	}
+	// a caller giving up returns straight away, it is not stuck behind the fetch
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.Token(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("cancelled caller err = %v", err)
+	}
+	close(release)
+	wg.Wait()
+	for i, token := range tokens {
+		if token != "token-1" {
+			t.Errorf("caller %d got %q", i, token)
+		}
+	}
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("%d fetches, want 1", got)
+	}
+}
+
+func TestOAuthClientTokenError(t *testing.T) {
+	server, _ := tokenServer(t, 3600, nil)
+	client := &OAuthClient{Config: OAuthConfig{TokenURL: server.URL, ClientID: "id", ClientSecret: "wrong"}}
+	_, err := client.Token(context.Background())
+	var tokenErr *OAuthTokenError
+	if !errors.As(err, &tokenErr) || tokenErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("err = %v, want a 401 *OAuthTokenError", err)
+	}
+}
+
+func TestOAuthTransportAddsBearer(t *testing.T) {
+	tokens, _ := tokenServer(t, 3600, nil)
+	var auth string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+	}))
+	defer api.Close()
+	client := &http.Client{Transport: NewOAuthTransport(nil, OAuthConfig{TokenURL: tokens.URL, ClientID: "id", ClientSecret: "secret"})}
+	response, err := client.Get(api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if auth != "Bearer token-1" {
+		t.Errorf("Authorization = %q", auth)
+	}
+}
+
+/* Records whether Close was called */
+type closeTrackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *closeTrackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestOAuthTransportClosesBodyOnTokenError(t *testing.T) {
+	tokens, _ := tokenServer(t, 3600, nil)
+	transport := NewOAuthTransport(nil, OAuthConfig{TokenURL: tokens.URL, ClientID: "id", ClientSecret: "wrong"})
+	body := &closeTrackingBody{Reader: strings.NewReader("payload")}
+	req, _ := http.NewRequest(http.MethodPost, "http://api.invalid/", body)
+	if _, err := transport.RoundTrip(req); err == nil {
+		t.Fatal("expected the token error")
+	}
+	if !body.closed {
+		t.Error("request body was not closed")
+	}
+}
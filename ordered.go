@@ -0,0 +1,146 @@
+package http_utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type orderedPair struct {
+	key   string
+	value interface{}
+}
+
+/*
+A json object that keeps its keys in document order, i.e. for diffing responses or APIs that care about parameter order.
+Nested objects decode as *OrderedMap, arrays as []interface{} and numbers as json.Number
+*/
+type OrderedMap struct {
+	pairs []orderedPair
+	index map[string]int
+}
+
+/* Decodes a json object into an *OrderedMap */
+func UnmarshalOrdered(data []byte) (*OrderedMap, error) {
+	m := &OrderedMap{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+/* The keys in document order, a repeated key keeps its first position and its last value */
+func (m *OrderedMap) Keys() []string {
+	keys := make([]string, len(m.pairs))
+	for i, pair := range m.pairs {
+		keys[i] = pair.key
+	}
+	return keys
+}
+
+/* The value for key, nil if it is missing */
+func (m *OrderedMap) Get(key string) interface{} {
+	if i, ok := m.index[key]; ok {
+		return m.pairs[i].value
+	}
+	return nil
+}
+
+func (m *OrderedMap) set(key string, value interface{}) {
+	if m.index == nil {
+		m.index = map[string]int{}
+	}
+	if i, ok := m.index[key]; ok {
+		m.pairs[i].value = value
+		return
+	}
+	m.index[key] = len(m.pairs)
+	m.pairs = append(m.pairs, orderedPair{key: key, value: value})
+}
+
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("OrderedMap: expected a json object, got %v", token)
+	}
+	m.pairs, m.index = nil, nil
+	return decodeOrderedObject(decoder, m)
+}
+
+/* Writes the keys back out in their order, HTML characters unescaped as in Marshal */
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, pair := range m.pairs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := Marshal(pair.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := Marshal(pair.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+/* Reads the members of an object whose opening { has been consumed, up to and including its closing } */
+func decodeOrderedObject(decoder *json.Decoder, m *OrderedMap) error {
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return errors.New("OrderedMap: expected an object key")
+		}
+		value, err := decodeOrderedValue(decoder)
+		if err != nil {
+			return err
+		}
+		m.set(key, value)
+	}
+	_, err := decoder.Token() // }
+	return err
+}
+
+func decodeOrderedValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		nested := &OrderedMap{}
+		if err := decodeOrderedObject(decoder, nested); err != nil {
+			return nil, err
+		}
+		return nested, nil
+	case json.Delim('['):
+		arr := []interface{}{}
+		for decoder.More() {
+			value, err := decodeOrderedValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := decoder.Token() // ]
+		return arr, err
+	}
+	return token, nil
+}
@@ -0,0 +1,174 @@
+package http_utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+/* Default upper bound on the pages CollectAllPages follows, so a next url that never ends cannot loop forever */
+const DefaultMaxPages = 1000
+
+/* Option for CollectAllPages and FetchAllPages */
+type PageOption func(*pageOptions)
+
+type pageOptions struct {
+	maxPages int
+}
+
+/* Allows n pages instead of DefaultMaxPages, less than 1 for the default */
+func WithMaxPages(n int) PageOption {
+	return func(o *pageOptions) {
+		if n > 0 {
+			o.maxPages = n
+		}
+	}
+}
+
+func newPageOptions(opts []PageOption) pageOptions {
+	o := pageOptions{maxPages: DefaultMaxPages}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+/*
+Follows a paginated API from firstURL, GETting each page and accumulating its items
+
+  - extractItems <func(body []byte) ([]T, string, error)> : returns the items of one page body and the url of the next page,
+    an empty url for the last page
+
+Stops with ctx.Err() when ctx is done, an *HTTPError for a non 2xx page, or an error after DefaultMaxPages pages
+(see WithMaxPages). The items collected so far are returned with any error
+*/
+func CollectAllPages[T any](ctx context.Context, firstURL string, extractItems func(body []byte) ([]T, string, error), opts ...PageOption) ([]T, error) {
+	o := newPageOptions(opts)
+	var all []T
+	url := firstURL
+	for page := 0; url != ""; page++ {
+		if page >= o.maxPages {
+			return all, fmt.Errorf("stopped after %d pages, next page %s", o.maxPages, url)
+		}
+		if err := ctx.Err(); err != nil {
+			return all, err
+		}
+		response, err := HttpPostReqContext(ctx, nil, http.MethodGet, nil, url, nil, nil)
+		if err != nil {
+			return all, err
+		}
+		if !response.StatusCode.IsSuccess() {
+			return all, &HTTPError{StatusCode: response.StatusCode, Status: response.Status, Body: response.Body}
+		}
+		items, next, err := extractItems(response.Body)
+		if err != nil {
+			return all, err
+		}
+		all = append(all, items...)
+		url = next
+	}
+	return all, nil
+}
+
+/*
+One page of a paginated API that reports its size up front, for FetchAllPages
+
+  - Page <int> : this page's number, pages are numbered from 1 when 0
+
+  - TotalPages <int> : total page count, 0 to work it out from TotalCount
+
+  - TotalCount <int> : total items across all pages
+
+  - PageSize <int> : items per page, 0 to take len(Items) of the first page
+*/
+type PageResponse[T any] struct {
+	Items      []T
+	Page       int
+	TotalPages int
+	TotalCount int
+	PageSize   int
+}
+
+func (p PageResponse[T]) totalPages() int {
+	if p.TotalPages > 0 {
+		return p.TotalPages
+	}
+	size := p.PageSize
+	if size <= 0 {
+		size = len(p.Items)
+	}
+	if size <= 0 {
+		return 1
+	}
+	return (p.TotalCount + size - 1) / size
+}
+
+/*
+Fetches every page after firstResp in parallel, with at most maxConcurrency (less than 1 for 1) fetchPage calls in flight,
+and returns the items of all pages in page order, firstResp's first
+
+  - fetchPage <func(ctx context.Context, page int) (PageResponse[T], error)> : fetches one page by number, only the Items of the result are used
+
+The first error cancels the pages still to fetch and is returned with no items.
+Fails without fetching when the page count is over DefaultMaxPages (see WithMaxPages)
+*/
+func FetchAllPages[T any](ctx context.Context, firstResp PageResponse[T], fetchPage func(ctx context.Context, page int) (PageResponse[T], error), maxConcurrency int, opts ...PageOption) ([]T, error) {
+	o := newPageOptions(opts)
+	first := firstResp.Page
+	if first == 0 {
+		first = 1
+	}
+	total := firstResp.totalPages()
+	if total > o.maxPages {
+		return nil, fmt.Errorf("%d pages is over the limit of %d", total, o.maxPages)
+	}
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+	remaining := total - first // pages first+1 .. total
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pages := make([][]T, remaining)
+	var firstErr error
+	var errOnce sync.Once
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < remaining; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+			page, err := fetchPage(ctx, first+1+i)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("page %d: %w", first+1+i, err)
+					cancel()
+				})
+				return
+			}
+			pages[i] = page.Items
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	all := append([]T(nil), firstResp.Items...)
+	for _, items := range pages {
+		all = append(all, items...)
+	}
+	return all, nil
+}
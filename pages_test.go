@@ -0,0 +1,130 @@
+package http_utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+type testPage struct {
+	Items []int  `json:"items"`
+	Next  string `json:"next"`
+}
+
+/* Serves /?page=N with items N*10, N*10+1 and a next link until lastPage, lastPage 0 for never ending */
+func pagedServer(t *testing.T, lastPage int) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		body := testPage{Items: []int{page * 10, page*10 + 1}}
+		if lastPage == 0 || page < lastPage {
+			body.Next = server.URL + "/?page=" + strconv.Itoa(page+1)
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func extractTestPage(body []byte) ([]int, string, error) {
+	var page testPage
+	err := json.Unmarshal(body, &page)
+	return page.Items, page.Next, err
+}
+
+func TestCollectAllPages(t *testing.T) {
+	server := pagedServer(t, 3)
+	items, err := CollectAllPages(context.Background(), server.URL+"/?page=1", extractTestPage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{10, 11, 20, 21, 30, 31}; !reflect.DeepEqual(items, want) {
+		t.Errorf("got %v, want %v", items, want)
+	}
+}
+
+func TestCollectAllPagesWithMaxPages(t *testing.T) {
+	server := pagedServer(t, 0)
+	items, err := CollectAllPages(context.Background(), server.URL+"/?page=1", extractTestPage, WithMaxPages(2))
+	if err == nil {
+		t.Fatal("expected an error after 2 pages")
+	}
+	if want := []int{10, 11, 20, 21}; !reflect.DeepEqual(items, want) {
+		t.Errorf("got %v, want the first 2 pages %v", items, want)
+	}
+}
+
+func TestCollectAllPagesCancelled(t *testing.T) {
+	server := pagedServer(t, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	pages := 0
+	_, err := CollectAllPages(ctx, server.URL+"/?page=1", func(body []byte) ([]int, string, error) {
+		if pages++; pages == 2 {
+			cancel()
+		}
+		return extractTestPage(body)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestCollectAllPagesHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	_, err := CollectAllPages(context.Background(), server.URL, extractTestPage)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("err = %v, want a 502 *HTTPError", err)
+	}
+}
+
+func fetchTestPage(ctx context.Context, page int) (PageResponse[int], error) {
+	return PageResponse[int]{Items: []int{page * 10, page*10 + 1}, Page: page}, nil
+}
+
+func TestFetchAllPagesInOrder(t *testing.T) {
+	first := PageResponse[int]{Items: []int{10, 11}, Page: 1, TotalCount: 8, PageSize: 2}
+	items, err := FetchAllPages(context.Background(), first, fetchTestPage, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{10, 11, 20, 21, 30, 31, 40, 41}; !reflect.DeepEqual(items, want) {
+		t.Errorf("got %v, want %v", items, want)
+	}
+}
+
+func TestFetchAllPagesWithMaxPages(t *testing.T) {
+	calls := 0
+	first := PageResponse[int]{Items: []int{10}, TotalPages: 5}
+	_, err := FetchAllPages(context.Background(), first, func(ctx context.Context, page int) (PageResponse[int], error) {
+		calls++
+		return fetchTestPage(ctx, page)
+	}, 1, WithMaxPages(4))
+	if err == nil || calls != 0 {
+		t.Errorf("err = %v after %d calls, want an error without fetching", err, calls)
+	}
+	if _, err := FetchAllPages(context.Background(), first, fetchTestPage, 1, WithMaxPages(5)); err != nil {
+		t.Errorf("5 pages within WithMaxPages(5): %v", err)
+	}
+}
+
+func TestFetchAllPagesError(t *testing.T) {
+	first := PageResponse[int]{Items: []int{10}, TotalPages: 10}
+	_, err := FetchAllPages(context.Background(), first, func(ctx context.Context, page int) (PageResponse[int], error) {
+		if page == 4 {
+			return PageResponse[int]{}, errors.New("page failed")
+		}
+		return fetchTestPage(ctx, page)
+	}, 2)
+	if err == nil || err.Error() != "page 4: page failed" {
+		t.Errorf("err = %v, want page 4's error", err)
+	}
+}
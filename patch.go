@@ -0,0 +1,52 @@
+package http_utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+/*
+Builds a partial update body for PATCH requests. partial is marshalled and every key it leaves null (a nil pointer field)
+that full has is dropped, so only the fields being changed are sent
+
+  - full <interface{}> : a value with every field of the resource, usually the zero value of its struct, only its keys are used
+
+  - partial <interface{}> : the changes, must marshal to a json object
+
+A null in partial for a key full does not have is kept, for APIs that clear fields with an explicit null, i.e.
+
+	type UserPatch struct {
+		Name  *string `json:"name"`
+		Email *string `json:"email"`
+	}
+	body, err := MarshalPatch(User{}, UserPatch{Email: Ptr("new@example.com")}) // {"email":"new@example.com"}
+*/
+func MarshalPatch(full, partial interface{}) ([]byte, error) {
+	fullKeys, err := marshalToObject(full)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling full: %w", err)
+	}
+	patch, err := marshalToObject(partial)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling partial: %w", err)
+	}
+	for key, value := range patch {
+		if _, ok := fullKeys[key]; ok && bytes.Equal(value, []byte("null")) {
+			delete(patch, key)
+		}
+	}
+	return Marshal(patch)
+}
+
+func marshalToObject(v interface{}) (map[string]json.RawMessage, error) {
+	data, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
@@ -0,0 +1,69 @@
+package http_utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+/* Returned by Ping when url could not be reached or did not respond with a 2xx or 3xx */
+type PingError struct {
+	URL string
+	Err error
+}
+
+func (e *PingError) Error() string {
+	return fmt.Sprintf("ping %s: %v", e.URL, e.Err)
+}
+
+func (e *PingError) Unwrap() error {
+	return e.Err
+}
+
+/* Redirects are not followed, a 3xx already shows the url is reachable */
+var pingConfig = &ClientConfig{MaxRedirects: Ptr(0)}
+
+/*
+Checks url is reachable with a HEAD request (a GET if HEAD gets 405 Method Not Allowed), i.e. before sending a large payload to it.
+Redirects are not followed, a 3xx counts as reachable.
+
+  - timeout <time.Duration> : limit for the whole check including the GET fallback, 0 for none beyond ctx
+
+Returns nil for any 2xx or 3xx, otherwise a *PingError with the cause (an *HTTPError for other status codes)
+*/
+func Ping(ctx context.Context, url string, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	client := pingConfig.httpClient()
+	resp, err := pingOnce(ctx, client, http.MethodHead, url)
+	if err == nil && resp.StatusCode == http.StatusMethodNotAllowed {
+		resp, err = pingOnce(ctx, client, http.MethodGet, url)
+	}
+	if err != nil {
+		return &PingError{URL: url, Err: err}
+	}
+	if code := StatusCode(resp.StatusCode); !code.IsSuccess() && !code.IsRedirect() {
+		return &PingError{URL: url, Err: &HTTPError{StatusCode: code, Status: resp.Status}}
+	}
+	return nil
+}
+
+/* Sends one request and closes the body without reading more than a little of it, for GETs of large resources */
+func pingOnce(ctx context.Context, client *http.Client, method, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	io.CopyN(io.Discard, resp.Body, 4096)
+	resp.Body.Close()
+	return resp, nil
+}
@@ -0,0 +1,95 @@
+package http_utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+/* Generates a self-signed certificate for 127.0.0.1, returning it for a server and PEM encoded for pinning */
+func selfSignedCert(t *testing.T) (tls.Certificate, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "pinning test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func pinnedServer(t *testing.T, cert tls.Certificate) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pinned"))
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCertificatePinningTransportAcceptsPinnedCert(t *testing.T) {
+	cert, certPEM := selfSignedCert(t)
+	server := pinnedServer(t, cert)
+
+	for name, pin := range map[string][]byte{"pem": certPEM, "der": cert.Certificate[0]} {
+		transport, err := CertificatePinningTransport([][]byte{pin})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		response, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("%s: status %d", name, response.StatusCode)
+		}
+	}
+}
+
+func TestCertificatePinningTransportRejectsOtherCert(t *testing.T) {
+	cert, _ := selfSignedCert(t)
+	_, otherPEM := selfSignedCert(t)
+	server := pinnedServer(t, cert)
+
+	transport, err := CertificatePinningTransport([][]byte{otherPEM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = (&http.Client{Transport: transport}).Get(server.URL)
+	if !errors.Is(err, ErrCertificateNotPinned) {
+		t.Errorf("err = %v, want ErrCertificateNotPinned", err)
+	}
+}
+
+func TestCertificatePinningTransportInvalidPins(t *testing.T) {
+	if _, err := CertificatePinningTransport(nil); err == nil {
+		t.Error("no pins: expected an error")
+	}
+	if _, err := CertificatePinningTransport([][]byte{[]byte("not a certificate")}); err == nil {
+		t.Error("invalid pin: expected an error")
+	}
+}
@@ -0,0 +1,110 @@
+package http_utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+One stage of a DecoderPipeline, returns the body to pass to the next step.
+Steps that only fill dest (UnmarshalStep) return body unchanged
+*/
+type PipelineStep func(body []byte, contentType string, dest interface{}) ([]byte, error)
+
+/* Runs a response body through its steps in order, i.e. NewDecoderPipeline(DecompressStep(""), DecryptStep(key), UnmarshalStep("json")) */
+type DecoderPipeline struct {
+	steps []PipelineStep
+}
+
+func NewDecoderPipeline(steps ...PipelineStep) *DecoderPipeline {
+	return &DecoderPipeline{steps: steps}
+}
+
+/* Passes body through every step, stopping at the first error. contentType and dest are handed to each step as is */
+func (p *DecoderPipeline) Decode(body []byte, contentType string, dest interface{}) error {
+	var err error
+	for i, step := range p.steps {
+		if body, err = step(body, contentType, dest); err != nil {
+			return fmt.Errorf("decoder pipeline step %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+/*
+Decompresses the body, encoding is "gzip" or "deflate" (zlib, as http Content-Encoding deflate is),
+or "" to gunzip bodies starting with the gzip magic bytes and pass anything else through
+*/
+func DecompressStep(encoding string) PipelineStep {
+	return func(body []byte, contentType string, dest interface{}) ([]byte, error) {
+		var reader io.ReadCloser
+		var err error
+		switch strings.ToLower(encoding) {
+		case "gzip":
+			reader, err = gzip.NewReader(bytes.NewReader(body))
+		case "deflate":
+			reader, err = zlib.NewReader(bytes.NewReader(body))
+		case "":
+			if len(body) < 2 || body[0] != 0x1f || body[1] != 0x8b {
+				return body, nil
+			}
+			reader, err = gzip.NewReader(bytes.NewReader(body))
+		default:
+			return nil, fmt.Errorf("unsupported encoding %q", encoding)
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	}
+}
+
+/*
+Decrypts an AES-GCM body laid out as nonce followed by ciphertext (with the tag appended, as cipher.AEAD.Seal produces).
+key must be 16, 24 or 32 bytes for AES-128, AES-192 or AES-256
+*/
+func DecryptStep(key []byte) PipelineStep {
+	return func(body []byte, contentType string, dest interface{}) ([]byte, error) {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		if len(body) < gcm.NonceSize() {
+			return nil, errors.New("encrypted body is shorter than the nonce")
+		}
+		nonce, ciphertext := body[:gcm.NonceSize()], body[gcm.NonceSize():]
+		return gcm.Open(nil, nonce, ciphertext, nil)
+	}
+}
+
+/* Unmarshals the body into dest, format is "json", "xml" or "" to pick by content type with DecodeBody */
+func UnmarshalStep(format string) PipelineStep {
+	return func(body []byte, contentType string, dest interface{}) ([]byte, error) {
+		var err error
+		switch strings.ToLower(format) {
+		case "json":
+			err = json.Unmarshal(body, dest)
+		case "xml":
+			err = xml.Unmarshal(body, dest)
+		case "":
+			err = DecodeBody(body, contentType, dest)
+		default:
+			err = fmt.Errorf("unsupported format %q", format)
+		}
+		return body, err
+	}
+}
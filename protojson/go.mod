@@ -0,0 +1,5 @@
+module github.com/rogue-syntax/http_utils/protojson
+
+go 1.21.0
+
+require google.golang.org/protobuf v1.34.2
@@ -0,0 +1,37 @@
+/*
+grpc-gateway compatible json helpers for http_utils, kept in their own module so the core module does not need google.golang.org/protobuf
+*/
+package protojson
+
+import (
+	"bytes"
+	"encoding/json"
+
+	gprotojson "google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+/*
+Decodes a grpc-gateway json response into a proto message. Both proto (snake_case) and json (lowerCamelCase) field names
+are accepted, and unknown fields are ignored, as http_utils.GetReqFromJSON does
+*/
+func ProtoJSONUnmarshal(data []byte, v protoreflect.ProtoMessage) error {
+	return gprotojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, v)
+}
+
+/*
+Encodes a proto message as grpc-gateway style json with proto (snake_case) field names.
+Like http_utils.Marshal, HTML characters are not escaped, and the output is compacted so it is stable between runs
+(protojson otherwise adds random whitespace)
+*/
+func ProtoJSONMarshal(m protoreflect.ProtoMessage) ([]byte, error) {
+	data, err := gprotojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,246 @@
+package http_utils
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/* The query param key for a struct field, the name from its `query:"name"` tag, otherwise keyEncoder(fieldName), nil for ToSnakeCase */
+func queryKey(field reflect.StructField, fieldName string, keyEncoder func(string) string) string {
+	if tag, ok := field.Tag.Lookup("query"); ok {
+		if name, _, _ := strings.Cut(tag, ","); name != "" {
+			return name
+		}
+	}
+	if keyEncoder == nil {
+		return ToSnakeCase(fieldName)
+	}
+	return keyEncoder(fieldName)
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+/*
+The inverse of RequestStructToquery, populates the pointer fields of dest from a raw query string
+
+  - rawQuery <string> : i.e. r.URL.RawQuery, a leading "?" is ignored
+
+  - dest <interface{}> : pointer to a request struct following the RequestStructToquery rules, fields are matched by
+    their `query:"key"` tag or snake-case name, slice fields also match "key[]"
+
+Fields without a matching param are left as is (nil), so absence can still be checked with nil
+*/
+func ParseQueryIntoStruct(rawQuery string, dest interface{}) error {
+	values, err := url.ParseQuery(strings.TrimPrefix(rawQuery, "?"))
+	if err != nil {
+		return err
+	}
+	val := reflect.ValueOf(dest)
+	if val.Kind() != reflect.Pointer || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dest must be a pointer to a struct, got %T", dest)
+	}
+	val = val.Elem()
+	typ := val.Type()
+	for i := 0; i < val.NumField(); i++ {
+		fieldType := typ.Field(i)
+		if !fieldType.IsExported() || fieldType.Type.Kind() != reflect.Pointer {
+			continue
+		}
+		key := queryKey(fieldType, fieldType.Name, nil)
+		params := append(values[key+"[]"], values[key]...)
+		if len(params) == 0 {
+			continue
+		}
+		ptr := reflect.New(fieldType.Type.Elem())
+		if err := setQueryValue(ptr, params); err != nil {
+			return fmt.Errorf("query param %s: %w", key, err)
+		}
+		val.Field(i).Set(ptr)
+	}
+	return nil
+}
+
+/* Sets the value ptr points to from params, the first param for single values or all of them for slices */
+func setQueryValue(ptr reflect.Value, params []string) error {
+	if ptr.Type().Implements(textUnmarshalerType) {
+		return ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(params[0]))
+	}
+	switch target := ptr.Interface().(type) {
+	case *big.Int:
+		if _, ok := target.SetString(params[0], 10); !ok {
+			return fmt.Errorf("invalid integer %q", params[0])
+		}
+		return nil
+	case *[]string:
+		*target = append([]string(nil), params...)
+		return nil
+	}
+
+	elem := ptr.Elem()
+	if elem.Kind() == reflect.Slice {
+		sli := reflect.MakeSlice(elem.Type(), len(params), len(params))
+		for i, param := range params {
+			if err := setScalar(sli.Index(i), param); err != nil {
+				return err
+			}
+		}
+		elem.Set(sli)
+		return nil
+	}
+	return setScalar(elem, params[0])
+}
+
+func setScalar(v reflect.Value, param string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(param)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(param)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(param, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(param, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+/* One failed rule from ValidateQueryStruct, i.e. {Field: "limit", Rule: "max=100", Value: "500"} */
+type QueryValidationError struct {
+	Field string // the query param key
+	Rule  string
+	Value string
+}
+
+func (e *QueryValidationError) Error() string {
+	return fmt.Sprintf("query param %s=%s fails %s", e.Field, e.Value, e.Rule)
+}
+
+/*
+Checks the non nil pointer fields of a request struct against the rules in their query tag, i.e.
+
+	type ListRequest struct {
+		Limit  *int    `query:"limit,min=1,max=100"`
+		Search *string `query:",minlen=3,maxlen=64"` // empty name keeps the snake-case key
+	}
+
+The rules are
+
+  - min, max : bounds for number fields (ints, uints, floats, *big.Int), inclusive
+
+  - minlen, maxlen : bounds on the length in characters of string fields
+
+Slice fields have each element checked. Returns nil when every rule passes, otherwise an errors.Join of a
+*QueryValidationError per failure, plus an error for each malformed rule
+*/
+func ValidateQueryStruct(req interface{}) error {
+	val := reflect.ValueOf(req)
+	if val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("req must be a struct or pointer to one, got %T", req)
+	}
+	var errs []error
+	typ := val.Type()
+	for i := 0; i < val.NumField(); i++ {
+		fieldType := typ.Field(i)
+		tag, ok := fieldType.Tag.Lookup("query")
+		field := val.Field(i)
+		if !ok || !fieldType.IsExported() || field.Kind() != reflect.Pointer || field.IsNil() {
+			continue
+		}
+		_, rules, _ := strings.Cut(tag, ",")
+		if rules == "" {
+			continue
+		}
+		key := queryKey(fieldType, fieldType.Name, nil)
+		values := []reflect.Value{field.Elem()}
+		if field.Elem().Kind() == reflect.Slice {
+			values = values[:0]
+			for j := 0; j < field.Elem().Len(); j++ {
+				values = append(values, field.Elem().Index(j))
+			}
+		}
+		for _, rule := range strings.Split(rules, ",") {
+			for _, value := range values {
+				if err := checkQueryRule(key, strings.TrimSpace(rule), value); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkQueryRule(key, rule string, value reflect.Value) error {
+	name, limitText, ok := strings.Cut(rule, "=")
+	if !ok {
+		return fmt.Errorf("query param %s: malformed rule %q", key, rule)
+	}
+	limit, err := strconv.ParseFloat(limitText, 64)
+	if err != nil {
+		return fmt.Errorf("query param %s: malformed rule %q: %w", key, rule, err)
+	}
+	var actual float64
+	var display string
+	switch name {
+	case "min", "max":
+		number, text, isNumber := queryNumber(value)
+		if !isNumber {
+			return fmt.Errorf("query param %s: rule %q needs a number field, got %s", key, rule, value.Type())
+		}
+		actual, display = number, text
+	case "minlen", "maxlen":
+		if value.Kind() != reflect.String {
+			return fmt.Errorf("query param %s: rule %q needs a string field, got %s", key, rule, value.Type())
+		}
+		actual, display = float64(utf8.RuneCountInString(value.String())), value.String()
+	default:
+		return fmt.Errorf("query param %s: unknown rule %q", key, rule)
+	}
+	if (strings.HasPrefix(name, "min") && actual < limit) || (strings.HasPrefix(name, "max") && actual > limit) {
+		return &QueryValidationError{Field: key, Rule: rule, Value: display}
+	}
+	return nil
+}
+
+/* value as a float64 for min and max and as text for errors, false if it is not a number */
+func queryNumber(value reflect.Value) (float64, string, bool) {
+	switch {
+	case value.CanInt():
+		return float64(value.Int()), strconv.FormatInt(value.Int(), 10), true
+	case value.CanUint():
+		return float64(value.Uint()), strconv.FormatUint(value.Uint(), 10), true
+	case value.CanFloat():
+		return value.Float(), strconv.FormatFloat(value.Float(), 'f', -1, 64), true
+	case value.Type() == reflect.TypeOf(big.Int{}):
+		n := value.Addr().Interface().(*big.Int)
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return f, n.String(), true
+	}
+	return 0, "", false
+}
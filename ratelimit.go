@@ -0,0 +1,173 @@
+package http_utils
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+/*
+Returns the client IP of a request from r.RemoteAddr. X-Forwarded-For and X-Real-IP are ignored, as any client can set them,
+use ClientIPBehindProxies when requests arrive through proxies you run
+*/
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+/*
+Returns the client IP of a request that may have come through trustedProxies, i.e. your load balancers.
+Only when r.RemoteAddr is a trusted proxy are the forwarded headers read: X-Forwarded-For is walked from the right,
+skipping trusted hops, and the first untrusted address is the client. Addresses left of it were set by the client and are ignored.
+X-Real-IP is used when a trusted proxy sent no X-Forwarded-For
+*/
+func ClientIPBehindProxies(r *http.Request, trustedProxies []netip.Prefix) string {
+	remote := ClientIP(r)
+	if !ipTrusted(remote, trustedProxies) {
+		return remote
+	}
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	if len(hops) == 0 {
+		if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+			return ip
+		}
+		return remote
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		if !ipTrusted(hops[i], trustedProxies) {
+			return hops[i]
+		}
+	}
+	// every hop is a trusted proxy, the left-most is the closest to the client
+	return hops[0]
+}
+
+func ipTrusted(ip string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+type ipLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen atomic.Int64 // unix nanos
+}
+
+/* How long an IP's limiter is kept after its last request when no ttl is given */
+const DefaultIPRateLimitTTL = 10 * time.Minute
+
+/* Option for IPRateLimiter and IPRateLimiterWithTTL */
+type IPRateLimitOption func(*ipRateLimitOptions)
+
+type ipRateLimitOptions struct {
+	trustedProxies []netip.Prefix
+}
+
+/* Limits by the client IP behind trusted proxies, see ClientIPBehindProxies, rather than by r.RemoteAddr */
+func TrustedProxies(prefixes ...netip.Prefix) IPRateLimitOption {
+	return func(o *ipRateLimitOptions) {
+		o.trustedProxies = append(o.trustedProxies, prefixes...)
+	}
+}
+
+/*
+IPRateLimiterWithTTL with DefaultIPRateLimitTTL, whose cleanup goroutine runs for the life of the process.
+Create it once rather than per request, or use IPRateLimiterWithTTL to be able to stop it
+*/
+func IPRateLimiter(rps float64, burst int, opts ...IPRateLimitOption) func(http.Handler) http.Handler {
+	return IPRateLimiterWithTTL(context.Background(), rps, burst, DefaultIPRateLimitTTL, opts...)
+}
+
+/*
+Middleware that limits each client IP (ClientIP, or ClientIPBehindProxies with TrustedProxies) to rps requests per second
+with bursts of up to burst, responding 429 Too Many Requests with a Retry-After header once the limit is hit
+
+  - ctx <context.Context> : stops the cleanup goroutine when done, the middleware keeps limiting but no longer evicts
+
+  - ttl <time.Duration> : how long an IP's limiter is kept after its last request, a cleanup goroutine evicts them every ttl.
+    0 or less for DefaultIPRateLimitTTL
+*/
+func IPRateLimiterWithTTL(ctx context.Context, rps float64, burst int, ttl time.Duration, opts ...IPRateLimitOption) func(http.Handler) http.Handler {
+	var options ipRateLimitOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if ttl <= 0 {
+		ttl = DefaultIPRateLimitTTL
+	}
+	var limiters sync.Map // string => *ipLimiter
+
+	go func() {
+		ticker := time.NewTicker(ttl)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				limiters.Range(func(key, value interface{}) bool {
+					if now.Sub(time.Unix(0, value.(*ipLimiter).lastSeen.Load())) > ttl {
+						limiters.Delete(key)
+					}
+					return true
+				})
+			}
+		}
+	}()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := ClientIP(r)
+			if len(options.trustedProxies) > 0 {
+				ip = ClientIPBehindProxies(r, options.trustedProxies)
+			}
+			value, ok := limiters.Load(ip)
+			if !ok {
+				value, _ = limiters.LoadOrStore(ip, &ipLimiter{limiter: rate.NewLimiter(rate.Limit(rps), burst)})
+			}
+			l := value.(*ipLimiter)
+			l.lastSeen.Store(time.Now().UnixNano())
+
+			reservation := l.limiter.Reserve()
+			if !reservation.OK() {
+				// burst of 0, no request can ever be allowed
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			if delay := reservation.Delay(); delay > 0 {
+				reservation.Cancel()
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
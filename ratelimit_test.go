@@ -0,0 +1,64 @@
+package http_utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+)
+
+func TestClientIPIgnoresForwardedHeaders(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "203.0.113.7:4321"
+	r.Header.Set("X-Forwarded-For", "1.2.3.4")
+	r.Header.Set("X-Real-IP", "5.6.7.8")
+	if got := ClientIP(r); got != "203.0.113.7" {
+		t.Errorf("ClientIP = %q, want the RemoteAddr host", got)
+	}
+}
+
+func TestClientIPBehindProxies(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	tests := []struct {
+		name, remote, forwarded, want string
+	}{
+		{"untrusted remote ignores headers", "203.0.113.7:1", "1.2.3.4", "203.0.113.7"},
+		{"right-most untrusted hop", "10.0.0.1:1", "6.6.6.6, 198.51.100.2, 10.0.0.5", "198.51.100.2"},
+		{"spoofed left-most hop is ignored", "10.0.0.1:1", "1.1.1.1, 198.51.100.2", "198.51.100.2"},
+		{"all hops trusted", "10.0.0.1:1", "10.0.0.9, 10.0.0.5", "10.0.0.9"},
+		{"no header", "10.0.0.1:1", "", "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if got := ClientIPBehindProxies(r, trusted); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIPRateLimiterNotBypassedBySpoofedHeader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handler := IPRateLimiterWithTTL(ctx, 1, 1, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	codes := make([]int, 3)
+	for i := range codes {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "203.0.113.7:1"
+		r.Header.Set("X-Forwarded-For", "1.2.3."+strconv.Itoa(i))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		codes[i] = w.Code
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("status codes %v, want [200 429 429]", codes)
+	}
+}
@@ -0,0 +1,61 @@
+package http_utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"runtime/debug"
+)
+
+/* Returns the request's X-Request-ID header, or a random 16 byte hex id if it has none */
+func RequestID(r *http.Request) string {
+	if id := r.Header.Get("X-Request-ID"); id != "" {
+		return id
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+/*
+Middleware that recovers panics in next, logs them with their stack trace through the package logger (see SetLogger)
+and responds 500 with {"error": "internal server error", "request_id": "..."}.
+http.ErrAbortHandler is re-panicked so net/http can abort the response as intended
+*/
+func RecoverMiddleware(next http.Handler) http.Handler {
+	return RecoverMiddlewareWithRenderer(nil)(next)
+}
+
+/*
+RecoverMiddleware rendering the 500 with render, i.e. one from NewErrorHandler for HTML error pages.
+The request id is set as the X-Request-ID response header before render is called. nil render gives RecoverMiddleware's json body
+*/
+func RecoverMiddlewareWithRenderer(render ErrorRenderer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				requestID := RequestID(r)
+				getLogger().Printf("panic serving %s %s (request_id %s): %v\n%s", r.Method, r.URL.Path, requestID, rec, debug.Stack())
+				w.Header().Set("X-Request-ID", requestID)
+				if render != nil {
+					render(http.StatusInternalServerError, "internal server error", w, r)
+					return
+				}
+				WriteJSON(w, http.StatusInternalServerError, map[string]string{
+					"error":      "internal server error",
+					"request_id": requestID,
+				})
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package http_utils
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+/*
+A per-request key value store carried in the request context, so middleware and handlers can share values
+without each defining their own context key types. Safe for concurrent use
+*/
+type RequestContext struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+}
+
+type requestContextKey struct{}
+
+/* Returns the value stored under key, nil if unset */
+func (rc *RequestContext) Get(key string) interface{} {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return rc.values[key]
+}
+
+func (rc *RequestContext) Set(key string, val interface{}) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.values == nil {
+		rc.values = make(map[string]interface{})
+	}
+	rc.values[key] = val
+}
+
+/* Returns a copy of ctx carrying a fresh, empty *RequestContext */
+func ContextWithRequestCtx(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, &RequestContext{})
+}
+
+/* Returns the *RequestContext in ctx, nil if there is none */
+func RequestCtxFromContext(ctx context.Context) *RequestContext {
+	rc, _ := ctx.Value(requestContextKey{}).(*RequestContext)
+	return rc
+}
+
+/* Middleware that attaches a fresh *RequestContext to every request, read it back with RequestCtxFromContext(r.Context()) */
+func InjectRequestContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(ContextWithRequestCtx(r.Context())))
+	})
+}
@@ -0,0 +1,73 @@
+package http_utils
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/* A fully read response */
+type Response struct {
+	StatusCode StatusCode
+	Status     string
+	Header     http.Header
+	Body       []byte
+}
+
+/* Returned for a response with an unexpected status code, Body holds the raw response body for logging */
+type HTTPError struct {
+	StatusCode StatusCode
+	Status     string
+	Body       []byte
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("unexpected response status %s", e.Status)
+}
+
+/* Parses the code out of a response.Status string i.e. "404 Not Found" => 404 */
+func ParseStatusCode(status string) (int, error) {
+	code, _, _ := strings.Cut(status, " ")
+	return strconv.Atoi(code)
+}
+
+/*
+Checks the status returned by HttpPostReq and decodes the body into a T
+
+  - body <[]byte> : response body from HttpPostReq
+
+  - status <string> : response status from HttpPostReq i.e. "200 OK"
+
+  - successCodes <[]int> : status codes to decode, nil for any 2xx
+
+Returns an *HTTPError for any other status code, and ErrEmptyBody for a success with no body (i.e. 204 No Content)
+*/
+func ReadResponseAs[T any](body []byte, status string, successCodes []int) (T, error) {
+	var result T
+	code, err := ParseStatusCode(status)
+	if err != nil {
+		return result, fmt.Errorf("invalid response status %q: %w", status, err)
+	}
+	if !isSuccessCode(code, successCodes) {
+		return result, &HTTPError{StatusCode: StatusCode(code), Status: status, Body: body}
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return result, ErrEmptyBody
+	}
+	err = Unmarshal(body, &result)
+	return result, err
+}
+
+func isSuccessCode(code int, successCodes []int) bool {
+	if len(successCodes) == 0 {
+		return code >= 200 && code < 300
+	}
+	for _, c := range successCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package http_utils
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"time"
+)
+
+/* Returns how long to wait before retry number attempt, attempt starts at 1 for the first retry */
+type BackoffFunc func(attempt int) time.Duration
+
+/* Waits d before every retry */
+func ConstantBackoff(d time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		return d
+	}
+}
+
+/* Waits initial, 2*initial, 3*initial ... */
+func LinearBackoff(initial time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		return initial * time.Duration(attempt)
+	}
+}
+
+/* Waits initial, initial*multiplier, initial*multiplier^2 ... up to max */
+func ExponentialBackoff(initial, max time.Duration, multiplier float64) BackoffFunc {
+	return func(attempt int) time.Duration {
+		return capDuration(float64(initial)*math.Pow(multiplier, float64(attempt-1)), max)
+	}
+}
+
+/*
+Waits a random duration between 0 and the exponential backoff for attempt (doubling from initial, up to max),
+which spreads out retries from many clients failing at once
+*/
+func FullJitterBackoff(initial, max time.Duration) BackoffFunc {
+	exponential := ExponentialBackoff(initial, max, 2)
+	return func(attempt int) time.Duration {
+		return FullJitter(exponential(attempt))
+	}
+}
+
+/* Waits d, returning ctx.Err() straight away if ctx is done first, nil once d has elapsed */
+func SleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func capDuration(d float64, max time.Duration) time.Duration {
+	if d > float64(max) || math.IsInf(d, 0) || math.IsNaN(d) {
+		return max
+	}
+	return time.Duration(d)
+}
+
+/*
+How to retry a request
+
+  - MaxAttempts <int> : total attempts including the first, less than 1 is treated as 1
+
+  - Backoff <BackoffFunc> : wait before each retry, nil to retry immediately
+
+  - RetryOn <func(statusCode int, err error) bool> : whether an attempt should be retried, statusCode is 0 when err is set.
+    nil retries on errors only
+*/
+type RetryConfig struct {
+	MaxAttempts int
+	Backoff     BackoffFunc
+	RetryOn     func(statusCode int, err error) bool
+}
+
+func (c RetryConfig) shouldRetry(statusCode int, err error) bool {
+	if c.RetryOn == nil {
+		return err != nil
+	}
+	return c.RetryOn(statusCode, err)
+}
+
+/*
+HttpPostReqContext retried according to retry. Waits between attempts are cut short when ctx is done.
+Once attempts run out the last response and error are returned as is
+*/
+func HttpPostReqWithRetry(ctx context.Context, retry RetryConfig, cfg *ClientConfig, method string, payload interface{}, url string, reqHeaders []ReqHeader, addHeaders []ReqHeader) (*Response, error) {
+	return retryWithStatus(ctx, func(ctx context.Context) (*Response, error) {
+		return HttpPostReqContext(ctx, cfg, method, payload, url, reqHeaders, addHeaders)
+	}, retry, func(response *Response) int {
+		if response == nil {
+			return 0
+		}
+		return int(response.StatusCode)
+	})
+}
+
+/*
+Calls fn until it succeeds or cfg says to stop, for retrying any fallible operation (database calls, cache lookups ...) with the same backoff as requests.
+cfg.RetryOn always gets a statusCode of 0. Waits between attempts are cut short when ctx is done, returning the last result with ctx.Err()
+*/
+func Retry[T any](ctx context.Context, fn func(ctx context.Context) (T, error), cfg RetryConfig) (T, error) {
+	return retryWithStatus(ctx, fn, cfg, nil)
+}
+
+/* The loop behind Retry and HttpPostReqWithRetry, statusOf (nil for always 0) gives the statusCode passed to RetryOn */
+func retryWithStatus[T any](ctx context.Context, fn func(ctx context.Context) (T, error), cfg RetryConfig, statusOf func(T) int) (T, error) {
+	var result T
+	var err error
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && cfg.Backoff != nil {
+			if err := SleepWithContext(ctx, cfg.Backoff(attempt)); err != nil {
+				return result, err
+			}
+		}
+		result, err = fn(ctx)
+		statusCode := 0
+		if statusOf != nil && err == nil {
+			statusCode = statusOf(result)
+		}
+		if attempt+1 >= cfg.MaxAttempts || !cfg.shouldRetry(statusCode, err) {
+			return result, err
+		}
+	}
+}
+
+/* A RetryConfig.RetryOn retrying on errors and on responses with any of codes, i.e. RetryOnStatusCodes(502, 503) */
+func RetryOnStatusCodes(codes ...int) func(int, error) bool {
+	return func(statusCode int, err error) bool {
+		if err != nil {
+			return true
+		}
+		for _, code := range codes {
+			if statusCode == code {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+/* A RetryConfig.RetryOn retrying on errors, any 5xx, 408 Request Timeout and 429 Too Many Requests */
+func RetryOnServerErrors() func(int, error) bool {
+	return func(statusCode int, err error) bool {
+		return err != nil || StatusCode(statusCode).IsServerError() ||
+			statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests
+	}
+}
+
+/* A RetryConfig.RetryOn that never retries, for tests */
+func RetryNever() func(int, error) bool {
+	return func(int, error) bool { return false }
+}
+
+/* A RetryConfig.RetryOn that retries every attempt until MaxAttempts, for tests */
+func RetryAlways() func(int, error) bool {
+	return func(int, error) bool { return true }
+}
+
+/* Functional option adjusting a RetryConfig, for per call retry settings i.e. RequestBuilder.Do(ctx, WithRetry(...)) */
+type RetryOption func(*RetryConfig)
+
+/* Total attempts including the first */
+func RetryMaxAttempts(n int) RetryOption {
+	return func(c *RetryConfig) {
+		c.MaxAttempts = n
+	}
+}
+
+func RetryBackoff(backoff BackoffFunc) RetryOption {
+	return func(c *RetryConfig) {
+		c.Backoff = backoff
+	}
+}
+
+/* Sets RetryConfig.RetryOn */
+func RetryWhen(retryOn func(statusCode int, err error) bool) RetryOption {
+	return func(c *RetryConfig) {
+		c.RetryOn = retryOn
+	}
+}
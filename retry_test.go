@@ -0,0 +1,62 @@
+package http_utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSleepWithContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	err := SleepWithContext(ctx, time.Second)
+	elapsed := time.Since(start)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("returned after %s, want shortly after the 10ms cancel", elapsed)
+	}
+}
+
+func TestSleepWithContextCompletes(t *testing.T) {
+	start := time.Now()
+	if err := SleepWithContext(context.Background(), 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("returned after %s, want at least 10ms", elapsed)
+	}
+}
+
+func TestRetryCancelledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	attempts := 0
+	start := time.Now()
+	_, err := Retry(ctx, func(ctx context.Context) (int, error) {
+		attempts++
+		return 0, errors.New("failed")
+	}, RetryConfig{MaxAttempts: 5, Backoff: ConstantBackoff(time.Second)})
+	if !errors.Is(err, context.Canceled) || attempts != 1 {
+		t.Errorf("err = %v after %d attempts, want context.Canceled after 1", err, attempts)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("returned after %s, the 1s backoff should have been cut short", elapsed)
+	}
+}
+
+func TestRetryStopsOnSuccess(t *testing.T) {
+	attempts := 0
+	got, err := Retry(context.Background(), func(ctx context.Context) (string, error) {
+		if attempts++; attempts < 3 {
+			return "", errors.New("failed")
+		}
+		return "ok", nil
+	}, RetryConfig{MaxAttempts: 5})
+	if err != nil || got != "ok" || attempts != 3 {
+		t.Errorf("got %q, %v after %d attempts", got, err, attempts)
+	}
+}
@@ -0,0 +1,33 @@
+package http_utils
+
+import "encoding/json"
+
+/*
+A Serialiser turns a request payload into bytes and names the Content-Type of those bytes.
+
+To send something other than json (Protocol Buffers, MessagePack etc), implement both methods
+on your own type and set it as ClientConfig.Serialiser, i.e.
+
+	type MsgPackSerialiser struct{}
+
+	func (MsgPackSerialiser) Marshal(i interface{}) ([]byte, error) { return msgpack.Marshal(i) }
+	func (MsgPackSerialiser) ContentType() string                   { return "application/msgpack" }
+
+ContentType() is only used for the default Content-Type header, if you pass your own reqHeaders
+it is up to you to set a matching Content-Type.
+*/
+type Serialiser interface {
+	Marshal(i interface{}) ([]byte, error)
+	ContentType() string
+}
+
+/* The default Serialiser, marshals payloads with encoding/json */
+type JSONSerialiser struct{}
+
+func (JSONSerialiser) Marshal(i interface{}) ([]byte, error) {
+	return json.Marshal(&i)
+}
+
+func (JSONSerialiser) ContentType() string {
+	return "application/json; charset=utf-8"
+}
@@ -0,0 +1,78 @@
+package http_utils
+
+/*
+A set of comparable values, i.e. for deduplicating items collected from overlapping pages with CollectAllPages
+
+	seen := NewSet[int]()
+	for _, item := range items {
+		if !seen.Contains(item.ID) {
+			seen.Add(item.ID)
+			unique = append(unique, item)
+		}
+	}
+
+The zero value is an empty set ready to use. ToSlice returns values in the order they were first added.
+Pass a Set around by pointer, as NewSet returns it, a copied Set would share some of its state with the original
+*/
+type Set[T comparable] struct {
+	index map[T]struct{}
+	order []T
+}
+
+/* A set holding values, duplicates are dropped */
+func NewSet[T comparable](values ...T) *Set[T] {
+	s := &Set[T]{}
+	for _, v := range values {
+		s.Add(v)
+	}
+	return s
+}
+
+func (s *Set[T]) Add(v T) {
+	if s.index == nil {
+		s.index = make(map[T]struct{})
+	}
+	if _, ok := s.index[v]; ok {
+		return
+	}
+	s.index[v] = struct{}{}
+	s.order = append(s.order, v)
+}
+
+/* Contains, ToSlice, Len and Intersect treat a nil *Set as empty */
+func (s *Set[T]) Contains(v T) bool {
+	if s == nil {
+		return false
+	}
+	_, ok := s.index[v]
+	return ok
+}
+
+/* The values in the order they were first added, a new slice the caller may modify */
+func (s *Set[T]) ToSlice() []T {
+	if s == nil {
+		return nil
+	}
+	return append([]T(nil), s.order...)
+}
+
+func (s *Set[T]) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.order)
+}
+
+/* A new set of the values in both s and other, in s's order */
+func (s *Set[T]) Intersect(other *Set[T]) *Set[T] {
+	intersection := &Set[T]{}
+	if s == nil {
+		return intersection
+	}
+	for _, v := range s.order {
+		if other.Contains(v) {
+			intersection.Add(v)
+		}
+	}
+	return intersection
+}
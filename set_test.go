@@ -0,0 +1,44 @@
+package http_utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetKeepsFirstAddedOrder(t *testing.T) {
+	s := NewSet(3, 1, 3, 2, 1)
+	if got := s.ToSlice(); !reflect.DeepEqual(got, []int{3, 1, 2}) {
+		t.Errorf("ToSlice() = %v", got)
+	}
+	if s.Len() != 3 || !s.Contains(2) || s.Contains(4) {
+		t.Errorf("Len %d, Contains(2) %v, Contains(4) %v", s.Len(), s.Contains(2), s.Contains(4))
+	}
+}
+
+func TestSetSharedThroughPointer(t *testing.T) {
+	s := NewSet("a")
+	other := s
+	other.Add("b")
+	if !s.Contains("b") || s.Len() != 2 || !reflect.DeepEqual(s.ToSlice(), []string{"a", "b"}) {
+		t.Errorf("an Add through another reference is not fully visible: %v", s.ToSlice())
+	}
+}
+
+func TestSetZeroValueAndNil(t *testing.T) {
+	var zero Set[int]
+	zero.Add(1)
+	if !zero.Contains(1) || zero.Len() != 1 {
+		t.Error("zero value Set is not usable")
+	}
+	var nilSet *Set[int]
+	if nilSet.Contains(1) || nilSet.Len() != 0 || nilSet.ToSlice() != nil || nilSet.Intersect(&zero).Len() != 0 {
+		t.Error("nil *Set is not empty")
+	}
+}
+
+func TestSetIntersect(t *testing.T) {
+	got := NewSet(4, 1, 2, 3).Intersect(NewSet(3, 4, 5))
+	if !reflect.DeepEqual(got.ToSlice(), []int{4, 3}) {
+		t.Errorf("Intersect = %v, want [4 3] in the receiver's order", got.ToSlice())
+	}
+}
@@ -0,0 +1,86 @@
+package http_utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* Returned by NewSSESender when the http.ResponseWriter cannot flush, so events would sit in a buffer */
+var ErrStreamingUnsupported = errors.New("http.ResponseWriter does not implement http.Flusher")
+
+/* Writes Server-Sent Events to an http handler's response, flushing after each one. Not safe for concurrent use */
+type SSESender struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+/*
+Starts an event stream on w, setting Content-Type: text/event-stream and Cache-Control: no-cache, i.e.
+
+	sender, err := NewSSESender(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for update := range updates {
+		if err := sender.SendEvent("update", update); err != nil {
+			return // client went away
+		}
+	}
+
+Headers are sent on the first write, call w.WriteHeader before sending for a status other than 200
+*/
+func NewSSESender(w http.ResponseWriter) (*SSESender, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	return &SSESender{w: w, flusher: flusher}, nil
+}
+
+/* Sends one event, eventType "" for the default "message" event. Multi-line data is sent as one data: field per line */
+func (s *SSESender) SendEvent(eventType, data string) error {
+	if strings.ContainsAny(eventType, "\r\n") {
+		return fmt.Errorf("sse event type %q contains a newline", eventType)
+	}
+	var event strings.Builder
+	if eventType != "" {
+		event.WriteString("event: " + eventType + "\n")
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		event.WriteString("data: " + line + "\n")
+	}
+	event.WriteString("\n")
+	return s.write(event.String())
+}
+
+/* Sends a comment line, ignored by clients, i.e. as a keep-alive through proxies that drop idle connections */
+func (s *SSESender) SendComment(comment string) error {
+	var lines strings.Builder
+	for _, line := range strings.Split(strings.ReplaceAll(comment, "\r\n", "\n"), "\n") {
+		lines.WriteString(": " + line + "\n")
+	}
+	lines.WriteString("\n")
+	return s.write(lines.String())
+}
+
+/* Tells the client how long to wait before reconnecting once the stream drops */
+func (s *SSESender) SetRetryInterval(d time.Duration) error {
+	return s.write("retry: " + strconv.FormatInt(d.Milliseconds(), 10) + "\n\n")
+}
+
+func (s *SSESender) write(text string) error {
+	if _, err := io.WriteString(s.w, text); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,103 @@
+package http_utils
+
+/* A response status code with predicates for its class, i.e. resp.StatusCode.IsSuccess() */
+type StatusCode int
+
+/* The standard status codes, matching the net/http constants */
+const (
+	StatusContinue           StatusCode = 100
+	StatusSwitchingProtocols StatusCode = 101
+	StatusProcessing         StatusCode = 102
+	StatusEarlyHints         StatusCode = 103
+
+	StatusOK                   StatusCode = 200
+	StatusCreated              StatusCode = 201
+	StatusAccepted             StatusCode = 202
+	StatusNonAuthoritativeInfo StatusCode = 203
+	StatusNoContent            StatusCode = 204
+	StatusResetContent         StatusCode = 205
+	StatusPartialContent       StatusCode = 206
+	StatusMultiStatus          StatusCode = 207
+	StatusAlreadyReported      StatusCode = 208
+	StatusIMUsed               StatusCode = 226
+
+	StatusMultipleChoices   StatusCode = 300
+	StatusMovedPermanently  StatusCode = 301
+	StatusFound             StatusCode = 302
+	StatusSeeOther          StatusCode = 303
+	StatusNotModified       StatusCode = 304
+	StatusUseProxy          StatusCode = 305
+	StatusTemporaryRedirect StatusCode = 307
+	StatusPermanentRedirect StatusCode = 308
+
+	StatusBadRequest                   StatusCode = 400
+	StatusUnauthorized                 StatusCode = 401
+	StatusPaymentRequired              StatusCode = 402
+	StatusForbidden                    StatusCode = 403
+	StatusNotFound                     StatusCode = 404
+	StatusMethodNotAllowed             StatusCode = 405
+	StatusNotAcceptable                StatusCode = 406
+	StatusProxyAuthRequired            StatusCode = 407
+	StatusRequestTimeout               StatusCode = 408
+	StatusConflict                     StatusCode = 409
+	StatusGone                         StatusCode = 410
+	StatusLengthRequired               StatusCode = 411
+	StatusPreconditionFailed           StatusCode = 412
+	StatusRequestEntityTooLarge        StatusCode = 413
+	StatusRequestURITooLong            StatusCode = 414
+	StatusUnsupportedMediaType         StatusCode = 415
+	StatusRequestedRangeNotSatisfiable StatusCode = 416
+	StatusExpectationFailed            StatusCode = 417
+	StatusTeapot                       StatusCode = 418
+	StatusMisdirectedRequest           StatusCode = 421
+	StatusUnprocessableEntity          StatusCode = 422
+	StatusLocked                       StatusCode = 423
+	StatusFailedDependency             StatusCode = 424
+	StatusTooEarly                     StatusCode = 425
+	StatusUpgradeRequired              StatusCode = 426
+	StatusPreconditionRequired         StatusCode = 428
+	StatusTooManyRequests              StatusCode = 429
+	StatusRequestHeaderFieldsTooLarge  StatusCode = 431
+	StatusUnavailableForLegalReasons   StatusCode = 451
+
+	StatusInternalServerError           StatusCode = 500
+	StatusNotImplemented                StatusCode = 501
+	StatusBadGateway                    StatusCode = 502
+	StatusServiceUnavailable            StatusCode = 503
+	StatusGatewayTimeout                StatusCode = 504
+	StatusHTTPVersionNotSupported       StatusCode = 505
+	StatusVariantAlsoNegotiates         StatusCode = 506
+	StatusInsufficientStorage           StatusCode = 507
+	StatusLoopDetected                  StatusCode = 508
+	StatusNotExtended                   StatusCode = 510
+	StatusNetworkAuthenticationRequired StatusCode = 511
+)
+
+/* 2xx */
+func (s StatusCode) IsSuccess() bool {
+	return s >= 200 && s < 300
+}
+
+/* 3xx */
+func (s StatusCode) IsRedirect() bool {
+	return s >= 300 && s < 400
+}
+
+/* 4xx */
+func (s StatusCode) IsClientError() bool {
+	return s >= 400 && s < 500
+}
+
+/* 5xx */
+func (s StatusCode) IsServerError() bool {
+	return s >= 500 && s < 600
+}
+
+/* Statuses worth retrying, 408, 429, 502, 503 and 504 */
+func (s StatusCode) IsRetryable() bool {
+	switch s {
+	case StatusRequestTimeout, StatusTooManyRequests, StatusBadGateway, StatusServiceUnavailable, StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package http_utils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/*
+Converts a struct (or pointer to struct) into a map of its exported fields, i.e. for templates or generic serialisation
+
+  - tagName <string> : struct tag to read key names from i.e. "json", fields without the tag use their Go name,
+    a tag name of "-" skips the field and ",omitempty" skips it when empty, as encoding/json does
+
+Values are the field values as is, nested structs are not converted
+*/
+func StructToMap(i interface{}, tagName string) (map[string]interface{}, error) {
+	val := reflect.ValueOf(i)
+	if val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return nil, fmt.Errorf("StructToMap: nil %T", i)
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("StructToMap: expected a struct, got %T", i)
+	}
+	typ := val.Type()
+	result := make(map[string]interface{}, val.NumField())
+	for i := 0; i < val.NumField(); i++ {
+		fieldType := typ.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+		key := fieldType.Name
+		omitEmpty := false
+		if tag, ok := fieldType.Tag.Lookup(tagName); ok {
+			name, opts, _ := strings.Cut(tag, ",")
+			if name == "-" && opts == "" {
+				continue
+			}
+			if name != "" {
+				key = name
+			}
+			for _, opt := range strings.Split(opts, ",") {
+				if opt == "omitempty" {
+					omitEmpty = true
+				}
+			}
+		}
+		field := val.Field(i)
+		if omitEmpty && isEmptyValue(field) {
+			continue
+		}
+		result[key] = field.Interface()
+	}
+	return result, nil
+}
+
+/* encoding/json's definition of empty for omitempty */
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package http_utils
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+/* Reports whether a and b are the same json ignoring object key order and whitespace, errors if either is not valid json */
+func JSONEqual(a, b []byte) (bool, error) {
+	var av, bv interface{}
+	if err := Unmarshal(a, &av); err != nil {
+		return false, err
+	}
+	if err := Unmarshal(b, &bv); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(av, bv), nil
+}
+
+/*
+Reports whether two query strings hold the same params regardless of order, i.e. "?b=2&a=1" and "a=1&b=2".
+A leading ? is ignored and the values of a repeated key may be in any order. Errors if either fails url.ParseQuery
+*/
+func QueryStringsEqual(a, b string) (bool, error) {
+	av, err := url.ParseQuery(strings.TrimPrefix(a, "?"))
+	if err != nil {
+		return false, err
+	}
+	bv, err := url.ParseQuery(strings.TrimPrefix(b, "?"))
+	if err != nil {
+		return false, err
+	}
+	if len(av) != len(bv) {
+		return false, nil
+	}
+	for key, values := range av {
+		other, ok := bv[key]
+		if !ok || len(values) != len(other) {
+			return false, nil
+		}
+		values, other = append([]string(nil), values...), append([]string(nil), other...)
+		sort.Strings(values)
+		sort.Strings(other)
+		if !reflect.DeepEqual(values, other) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,47 @@
+package http_utils
+
+import "testing"
+
+func TestJSONEqual(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		equal bool
+	}{
+		{`{"a":1,"b":[1,2]}`, `{ "b": [1, 2], "a": 1 }`, true},
+		{`{"a":1}`, `{"a":2}`, false},
+		{`[1,2]`, `[2,1]`, false},
+		{`null`, `null`, true},
+	}
+	for _, tt := range tests {
+		equal, err := JSONEqual([]byte(tt.a), []byte(tt.b))
+		if err != nil || equal != tt.equal {
+			t.Errorf("JSONEqual(%s, %s) = %v, %v, want %v", tt.a, tt.b, equal, err, tt.equal)
+		}
+	}
+	if _, err := JSONEqual([]byte(`{`), []byte(`{}`)); err == nil {
+		t.Error("invalid json: expected an error")
+	}
+}
+
+func TestQueryStringsEqual(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		equal bool
+	}{
+		{"?b=2&a=1", "a=1&b=2", true},
+		{"a=1&a=2", "a=2&a=1", true},
+		{"a=1&a=2", "a=1", false},
+		{"a=1", "b=1", false},
+		{"a=1", "a=1&b=2", false},
+		{"", "?", true},
+	}
+	for _, tt := range tests {
+		equal, err := QueryStringsEqual(tt.a, tt.b)
+		if err != nil || equal != tt.equal {
+			t.Errorf("QueryStringsEqual(%q, %q) = %v, %v, want %v", tt.a, tt.b, equal, err, tt.equal)
+		}
+	}
+	if _, err := QueryStringsEqual("a=%zz", "a=1"); err == nil {
+		t.Error("invalid query: expected an error")
+	}
+}
@@ -0,0 +1,58 @@
+package http_utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+/* A request that timed out or was cancelled, from WrapTimeoutError */
+type TimeoutError struct {
+	URL      string
+	Method   string
+	Duration time.Duration // how long the request ran, 0 when unknown, WrapTimeoutError leaves it for the caller to fill in
+	Err      error
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Duration > 0 {
+		return fmt.Sprintf("%s %s timed out after %s: %v", e.Method, e.URL, e.Duration, e.Err)
+	}
+	return fmt.Sprintf("%s %s timed out: %v", e.Method, e.URL, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+/* Whether err is, or wraps, context.DeadlineExceeded, context.Canceled or a net.Error reporting Timeout() */
+func IsTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+/*
+Wraps err in a *TimeoutError for url and method when IsTimeoutError(err), any other error (or nil) is returned as is i.e.
+
+	start := time.Now()
+	response, err := HttpPostReqContext(ctx, cfg, "GET", nil, url, nil, nil)
+	err = WrapTimeoutError(err, url, "GET")
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		timeoutErr.Duration = time.Since(start)
+	}
+*/
+func WrapTimeoutError(err error, url, method string) error {
+	if !IsTimeoutError(err) {
+		return err
+	}
+	return &TimeoutError{URL: url, Method: method, Err: err}
+}
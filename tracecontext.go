@@ -0,0 +1,65 @@
+package http_utils
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+)
+
+/* W3C TraceContext headers, see https://www.w3.org/TR/trace-context/ */
+type TraceContext struct {
+	TraceParent string
+	TraceState  string
+}
+
+type traceContextKey struct{}
+
+/* version-traceid-parentid-flags, all lowercase hex */
+var matchTraceParent = regexp.MustCompile("^[0-9a-f]{2}-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$")
+
+/* Reports whether traceparent is well formed, an all zero trace id or parent id and version ff are invalid */
+func ValidTraceParent(traceparent string) bool {
+	if !matchTraceParent.MatchString(traceparent) {
+		return false
+	}
+	return traceparent[:2] != "ff" &&
+		traceparent[3:35] != "00000000000000000000000000000000" &&
+		traceparent[36:52] != "0000000000000000"
+}
+
+/*
+Middleware that stores a valid incoming traceparent (and its tracestate) in the request context,
+for InjectTraceContext to forward on outgoing requests. Invalid traceparents are dropped, as the spec requires
+*/
+func PropagateTraceContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent := r.Header.Get("traceparent")
+		if ValidTraceParent(traceparent) {
+			tc := TraceContext{TraceParent: traceparent, TraceState: r.Header.Get("tracestate")}
+			r = r.WithContext(context.WithValue(r.Context(), traceContextKey{}, tc))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+/* Returns the TraceContext stored by PropagateTraceContext */
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return tc, ok
+}
+
+/*
+Returns headers with the traceparent and tracestate stored in ctx added, for the addHeaders argument of HttpPostReq.
+headers is returned as is when ctx has no trace context, and is never modified
+*/
+func InjectTraceContext(ctx context.Context, headers []ReqHeader) []ReqHeader {
+	tc, ok := TraceContextFromContext(ctx)
+	if !ok {
+		return headers
+	}
+	headers = append(headers[:len(headers):len(headers)], ReqHeader{HeaderName: "traceparent", HeaderValue: tc.TraceParent})
+	if tc.TraceState != "" {
+		headers = append(headers, ReqHeader{HeaderName: "tracestate", HeaderValue: tc.TraceState})
+	}
+	return headers
+}
@@ -0,0 +1,147 @@
+package http_utils
+
+import (
+	"context"
+	"expvar"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+Connection counts for an *http.Transport, see ExposeTransportStats.
+net/http does not expose its pool, so open connections are counted at the dialer and active ones by RoundTripper()
+*/
+type TransportStats struct {
+	transport *http.Transport
+	open      atomic.Int64
+	inFlight  atomic.Int64
+}
+
+/* Connections dialed and not yet closed */
+func (s *TransportStats) OpenConnCount() int64 {
+	return s.open.Load()
+}
+
+/* Requests currently being sent through RoundTripper(), each holds a connection */
+func (s *TransportStats) ActiveConnCount() int64 {
+	return s.inFlight.Load()
+}
+
+/* Open connections not serving a request, only accurate when all traffic goes through RoundTripper() */
+func (s *TransportStats) IdleConnCount() int64 {
+	idle := s.open.Load() - s.inFlight.Load()
+	if idle < 0 {
+		return 0
+	}
+	return idle
+}
+
+/* Always 0, the Go resolver does not cache lookups, kept so dashboards expecting the metric have one */
+func (s *TransportStats) DNSCacheSize() int64 {
+	return 0
+}
+
+/* The transport wrapped to count in flight requests, use it as the http.Client Transport for ActiveConnCount */
+func (s *TransportStats) RoundTripper() http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		s.inFlight.Add(1)
+		resp, err := s.transport.RoundTrip(req)
+		if err != nil {
+			s.inFlight.Add(-1)
+			return nil, err
+		}
+		// the connection is held until the body is closed
+		var once sync.Once
+		resp.Body = &closeHook{ReadCloser: resp.Body, onClose: func() { once.Do(func() { s.inFlight.Add(-1) }) }}
+		return resp, nil
+	})
+}
+
+func (s *TransportStats) snapshot() map[string]int64 {
+	return map[string]int64{
+		"IdleConnCount":   s.IdleConnCount(),
+		"ActiveConnCount": s.ActiveConnCount(),
+		"OpenConnCount":   s.OpenConnCount(),
+		"DNSCacheSize":    s.DNSCacheSize(),
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type countedConn struct {
+	net.Conn
+	once    sync.Once
+	onClose func()
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(c.onClose)
+	return c.Conn.Close()
+}
+
+var (
+	transportStatsMu sync.Mutex
+	transportStats   = map[string]*TransportStats{}
+)
+
+/*
+Counts t's connections and publishes them with expvar as <name>.IdleConnCount, <name>.ActiveConnCount, <name>.OpenConnCount
+and <name>.DNSCacheSize, alongside the standard /debug/vars. Wraps t.DialContext, so call it before t is first used.
+Like expvar.Publish, panics if name is already used
+*/
+func ExposeTransportStats(t *http.Transport, name string) *TransportStats {
+	stats := &TransportStats{transport: t}
+	dial := t.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		stats.open.Add(1)
+		return &countedConn{Conn: conn, onClose: func() { stats.open.Add(-1) }}, nil
+	}
+
+	expvar.Publish(name+".IdleConnCount", expvar.Func(func() interface{} { return stats.IdleConnCount() }))
+	expvar.Publish(name+".ActiveConnCount", expvar.Func(func() interface{} { return stats.ActiveConnCount() }))
+	expvar.Publish(name+".OpenConnCount", expvar.Func(func() interface{} { return stats.OpenConnCount() }))
+	expvar.Publish(name+".DNSCacheSize", expvar.Func(func() interface{} { return stats.DNSCacheSize() }))
+
+	transportStatsMu.Lock()
+	transportStats[name] = stats
+	transportStatsMu.Unlock()
+	return stats
+}
+
+/* Default path for HandleTransportStats */
+const DefaultTransportStatsPath = "/debug/transport"
+
+/* Serves every transport passed to ExposeTransportStats as json, {"<name>": {"IdleConnCount": 1, ...}} */
+func TransportStatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transportStatsMu.Lock()
+		all := make(map[string]map[string]int64, len(transportStats))
+		for name, stats := range transportStats {
+			all[name] = stats.snapshot()
+		}
+		transportStatsMu.Unlock()
+		WriteJSON(w, http.StatusOK, all)
+	})
+}
+
+/* Registers TransportStatsHandler on mux at path, "" for DefaultTransportStatsPath */
+func HandleTransportStats(mux *http.ServeMux, path string) {
+	if path == "" {
+		path = DefaultTransportStatsPath
+	}
+	mux.Handle(path, TransportStatsHandler())
+}
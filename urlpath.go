@@ -0,0 +1,97 @@
+package http_utils
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+/* A parsed path template with {key} placeholders, from ParseURLTemplate */
+type URLTemplate struct {
+	template string
+	literals []string // literal text around the placeholders, always len(names)+1
+	names    []string // placeholder names in template order, may repeat
+}
+
+/*
+Parses a path template like "/users/{userId}/orders/{orderId}" once, for expanding it many times.
+Errors if the template has an unclosed or empty placeholder or a stray }
+*/
+func ParseURLTemplate(template string) (*URLTemplate, error) {
+	t := &URLTemplate{template: template}
+	rest := template
+	for {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return nil, fmt.Errorf("path template %q: unexpected }", template)
+			}
+			t.literals = append(t.literals, rest)
+			return t, nil
+		}
+		if strings.IndexByte(rest[:open], '}') >= 0 {
+			return nil, fmt.Errorf("path template %q: unexpected }", template)
+		}
+		end := strings.IndexAny(rest[open+1:], "{}")
+		if end < 0 || rest[open+1+end] != '}' {
+			return nil, fmt.Errorf("path template %q: unclosed {", template)
+		}
+		name := rest[open+1 : open+1+end]
+		if name == "" {
+			return nil, fmt.Errorf("path template %q: empty placeholder {}", template)
+		}
+		t.literals = append(t.literals, rest[:open])
+		t.names = append(t.names, name)
+		rest = rest[open+1+end+1:]
+	}
+}
+
+/* The placeholder names in the order they first appear, each listed once */
+func (t *URLTemplate) RequiredParams() []string {
+	var required []string
+	seen := map[string]bool{}
+	for _, name := range t.names {
+		if !seen[name] {
+			seen[name] = true
+			required = append(required, name)
+		}
+	}
+	return required
+}
+
+/* Fills the placeholders with url.PathEscape'd values, erroring with every placeholder missing from params */
+func (t *URLTemplate) Expand(params map[string]string) (string, error) {
+	var missing []string
+	for _, name := range t.RequiredParams() {
+		if _, ok := params[name]; !ok {
+			missing = append(missing, "{"+name+"}")
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("path template %q: no value for %s", t.template, strings.Join(missing, ", "))
+	}
+	var expanded strings.Builder
+	for i, name := range t.names {
+		expanded.WriteString(t.literals[i])
+		expanded.WriteString(url.PathEscape(params[name]))
+	}
+	expanded.WriteString(t.literals[len(t.literals)-1])
+	return expanded.String(), nil
+}
+
+/*
+Fills the {key} placeholders of a path template with url.PathEscape'd values, i.e.
+
+	ExpandPath("/users/{userId}/orders/{orderId}", map[string]string{"userId": "42", "orderId": "a/b"})
+	// "/users/42/orders/a%2Fb"
+
+Errors if a placeholder has no value in params, or the template has an unclosed or empty placeholder.
+Use ParseURLTemplate to parse a template once and check RequiredParams up front
+*/
+func ExpandPath(template string, params map[string]string) (string, error) {
+	t, err := ParseURLTemplate(template)
+	if err != nil {
+		return "", err
+	}
+	return t.Expand(params)
+}
@@ -0,0 +1,66 @@
+package http_utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		params   map[string]string
+		want     string
+	}{
+		{"plain", "/users/{userId}/orders/{orderId}", map[string]string{"userId": "42", "orderId": "7"}, "/users/42/orders/7"},
+		{"slash escaped", "/files/{name}", map[string]string{"name": "a/b"}, "/files/a%2Fb"},
+		{"special characters", "/q/{v}", map[string]string{"v": "a b?c#d%e&f"}, "/q/a%20b%3Fc%23d%25e&f"},
+		{"non-ASCII", "/users/{name}", map[string]string{"name": "Zoë 日本"}, "/users/Zo%C3%AB%20%E6%97%A5%E6%9C%AC"},
+		{"repeated placeholder", "/{id}/{id}", map[string]string{"id": "x"}, "/x/x"},
+		{"empty value", "/users/{id}/", map[string]string{"id": ""}, "/users//"},
+		{"no placeholders", "/health", nil, "/health"},
+		{"extra params ignored", "/users/{id}", map[string]string{"id": "1", "other": "2"}, "/users/1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandPath(tt.template, tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandPath(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandPathErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		params   map[string]string
+	}{
+		{"missing param", "/users/{userId}/orders/{orderId}", map[string]string{"userId": "1"}},
+		{"unclosed brace", "/users/{userId", map[string]string{"userId": "1"}},
+		{"nested brace", "/users/{user{Id}", map[string]string{"userId": "1"}},
+		{"stray closing brace", "/users/userId}", nil},
+		{"empty placeholder", "/users/{}", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ExpandPath(tt.template, tt.params); err == nil {
+				t.Errorf("ExpandPath(%q) = %q, expected an error", tt.template, got)
+			}
+		})
+	}
+}
+
+func TestURLTemplateRequiredParams(t *testing.T) {
+	tmpl, err := ParseURLTemplate("/{b}/{a}/{b}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tmpl.RequiredParams(); !reflect.DeepEqual(got, []string{"b", "a"}) {
+		t.Errorf("RequiredParams() = %v", got)
+	}
+}
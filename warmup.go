@@ -0,0 +1,66 @@
+package http_utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+/*
+Sends a HEAD request to each url, at most concurrency at a time, so client's connection pool holds open keep-alive
+connections (TCP and TLS handshakes done) before real traffic arrives
+
+  - client <*http.Client> : the client to warm up, nil for http.DefaultClient
+
+  - concurrency <int> : requests in flight at once, less than 1 sends them all at once.
+    Connections kept per host are still capped by the transport's MaxIdleConnsPerHost
+
+Returns nil when every url responded, otherwise an errors.Join of one error per failed url. Any response counts as
+success, as a 405 for HEAD still leaves a warm connection
+*/
+func WarmUp(ctx context.Context, client *http.Client, urls []string, concurrency int) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if concurrency < 1 {
+		concurrency = len(urls)
+	}
+	sem := make(chan struct{}, concurrency)
+	errs := make([]error, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = fmt.Errorf("warming up %s: %w", url, ctx.Err())
+				return
+			}
+			defer func() { <-sem }()
+			if err := warmOne(ctx, client, url); err != nil {
+				errs[i] = fmt.Errorf("warming up %s: %w", url, err)
+			}
+		}(i, url)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func warmOne(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	// drained and closed so the connection goes back to the pool
+	io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
@@ -0,0 +1,5 @@
+module github.com/rogue-syntax/http_utils/websocket
+
+go 1.21.0
+
+require golang.org/x/net v0.25.0
@@ -0,0 +1,156 @@
+/*
+WebSocket helpers for http_utils, kept in their own module so the core module does not need golang.org/x/net/websocket
+*/
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	xws "golang.org/x/net/websocket"
+)
+
+/* Message types returned by WSClient.ReadMessage */
+const (
+	TextMessage   = xws.TextFrame
+	BinaryMessage = xws.BinaryFrame
+)
+
+/* RFC 6455 magic value for the Sec-WebSocket-Accept hash */
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+/*
+Performs the RFC 6455 server handshake and hijacks the connection, for callers that want to handle frames themselves.
+Most handlers should use Handler instead
+
+  - responseHeader <http.Header> : nil or extra headers for the 101 response i.e. Sec-WebSocket-Protocol
+
+On a bad handshake a 400 response is written and an error returned. The caller owns the returned net.Conn and must close it
+*/
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request, responseHeader http.Header) (net.Conn, *bufio.ReadWriter, error) {
+	if r.Method != http.MethodGet ||
+		!headerContainsToken(r.Header, "Connection", "upgrade") ||
+		!headerContainsToken(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "not a websocket handshake", http.StatusBadRequest)
+		return nil, nil, errors.New("websocket: not a websocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusBadRequest)
+		return nil, nil, errors.New("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, nil, errors.New("websocket: missing Sec-WebSocket-Key")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket upgrade not supported", http.StatusInternalServerError)
+		return nil, nil, errors.New("websocket: response writer does not implement http.Hijacker")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hash := sha1.Sum([]byte(key + acceptGUID))
+	header := http.Header{}
+	for name, values := range responseHeader {
+		header[name] = values
+	}
+	header.Set("Upgrade", "websocket")
+	header.Set("Connection", "Upgrade")
+	header.Set("Sec-WebSocket-Accept", base64.StdEncoding.EncodeToString(hash[:]))
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	header.Write(rw)
+	rw.WriteString("\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, rw, nil
+}
+
+func headerContainsToken(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/* A websocket connection with whole-message send and receive, from Dial or Handler */
+type WSClient struct {
+	conn *xws.Conn
+}
+
+/*
+Connects to a websocket server
+
+  - url <string> : ws:// or wss:// url
+
+  - origin <string> : value for the Origin header, i.e. "http://localhost/"
+*/
+func Dial(url, origin string) (*WSClient, error) {
+	conn, err := xws.Dial(url, "", origin)
+	if err != nil {
+		return nil, err
+	}
+	return &WSClient{conn: conn}, nil
+}
+
+/* An http.Handler that accepts websocket connections and calls fn with each, the connection is closed when fn returns */
+func Handler(fn func(client *WSClient)) http.Handler {
+	return xws.Handler(func(conn *xws.Conn) {
+		fn(&WSClient{conn: conn})
+	})
+}
+
+type wsMessage struct {
+	messageType int
+	data        []byte
+}
+
+var messageCodec = xws.Codec{
+	Marshal: func(v interface{}) ([]byte, byte, error) {
+		m := v.(wsMessage)
+		return m.data, byte(m.messageType), nil
+	},
+	Unmarshal: func(data []byte, payloadType byte, v interface{}) error {
+		m := v.(*wsMessage)
+		m.messageType = int(payloadType)
+		m.data = data
+		return nil
+	},
+}
+
+func (c *WSClient) SendText(msg string) error {
+	return messageCodec.Send(c.conn, wsMessage{messageType: TextMessage, data: []byte(msg)})
+}
+
+func (c *WSClient) SendBinary(data []byte) error {
+	return messageCodec.Send(c.conn, wsMessage{messageType: BinaryMessage, data: data})
+}
+
+/* Blocks until the next message arrives, messageType is TextMessage or BinaryMessage */
+func (c *WSClient) ReadMessage() (messageType int, data []byte, err error) {
+	var m wsMessage
+	if err := messageCodec.Receive(c.conn, &m); err != nil {
+		return 0, nil, err
+	}
+	return m.messageType, m.data, nil
+}
+
+func (c *WSClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,135 @@
+package http_utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+/* Writes v as a json response with statusCode, using Marshal so HTML characters are not escaped */
+func WriteJSON(w http.ResponseWriter, statusCode int, v interface{}) error {
+	body, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	_, err = w.Write(body)
+	return err
+}
+
+/* Writes text as a text/plain response with statusCode */
+func WritePlainText(w http.ResponseWriter, statusCode int, text string) error {
+	return writeString(w, statusCode, "text/plain; charset=utf-8", text)
+}
+
+/* Writes html as a text/html response with statusCode, html is written as is so it must already be escaped */
+func WriteHTML(w http.ResponseWriter, statusCode int, html string) error {
+	return writeString(w, statusCode, "text/html; charset=utf-8", html)
+}
+
+func writeString(w http.ResponseWriter, statusCode int, contentType string, body string) error {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+	_, err := io.WriteString(w, body)
+	return err
+}
+
+/*
+Writes items as a json array response with statusCode, encoding one item at a time straight to w so the full json is never held in memory.
+HTML characters are not escaped, as in WriteJSON. An error part way through leaves a truncated array, as the status is already sent
+*/
+func WriteJSONStream[T any](w http.ResponseWriter, statusCode int, items []T) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i, item := range items {
+		if i > 0 {
+			if _, err := w.Write(jsonComma); err != nil {
+				return err
+			}
+		}
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(jsonCloseBracket)
+	return err
+}
+
+/* Streams a json array one element at a time, flushing after each, see FlushingJSONArrayWriter */
+type JSONArrayWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher // nil when w cannot flush
+	encoder *json.Encoder
+	count   int
+	err     error // sticky, the first write error
+}
+
+/*
+Starts a streamed json array response on w, writing the opening [ straight away (and so a 200 status, set one first with w.WriteHeader for another).
+Each Write is flushed so clients can start processing before the array is complete. Call Close to finish the array
+*/
+func FlushingJSONArrayWriter(w http.ResponseWriter) *JSONArrayWriter {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	writer := &JSONArrayWriter{w: w, encoder: encoder}
+	writer.flusher, _ = w.(http.Flusher)
+	_, writer.err = io.WriteString(w, "[")
+	writer.flush()
+	return writer
+}
+
+/* Encodes item as the next array element and flushes it */
+func (a *JSONArrayWriter) Write(item interface{}) error {
+	if a.err != nil {
+		return a.err
+	}
+	if a.count > 0 {
+		if _, a.err = a.w.Write(jsonComma); a.err != nil {
+			return a.err
+		}
+	}
+	if a.err = a.encoder.Encode(item); a.err != nil {
+		return a.err
+	}
+	a.count++
+	a.flush()
+	return nil
+}
+
+/* Writes the closing ] and flushes, nothing else can be written after */
+func (a *JSONArrayWriter) Close() error {
+	if a.err != nil {
+		return a.err
+	}
+	_, a.err = a.w.Write(jsonCloseBracket)
+	a.flush()
+	if a.err != nil {
+		return a.err
+	}
+	a.err = errJSONArrayClosed
+	return nil
+}
+
+// io.WriteString(w, ",") allocates per call when w has no WriteString
+var (
+	jsonComma        = []byte(",")
+	jsonCloseBracket = []byte("]")
+)
+
+var errJSONArrayClosed = errors.New("http_utils: JSONArrayWriter is closed")
+
+func (a *JSONArrayWriter) flush() {
+	if a.flusher != nil && a.err == nil {
+		a.flusher.Flush()
+	}
+}
@@ -0,0 +1,138 @@
+package http_utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type streamItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestWriteJSONStream(t *testing.T) {
+	items := []streamItem{{1, "a<b>"}, {2, "c&d"}}
+	w := httptest.NewRecorder()
+	if err := WriteJSONStream(w, http.StatusCreated, items); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusCreated || w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Errorf("status %d, Content-Type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	var got []streamItem
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json %q: %v", w.Body.String(), err)
+	}
+	if !reflect.DeepEqual(got, items) {
+		t.Errorf("got %v, want %v", got, items)
+	}
+	if !json.Valid(w.Body.Bytes()) || w.Body.String()[0:2] != `[{` {
+		t.Errorf("body %q", w.Body.String())
+	}
+}
+
+func TestWriteJSONStreamEmpty(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := WriteJSONStream(w, http.StatusOK, []streamItem(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if w.Body.String() != "[]" {
+		t.Errorf("body %q, want []", w.Body.String())
+	}
+}
+
+/* A ResponseWriter throwing the body away, so benchmarks measure encoding rather than buffering the output */
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (d *discardResponseWriter) Header() http.Header         { return d.header }
+func (d *discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (d *discardResponseWriter) WriteHeader(int)             {}
+
+func benchmarkStreamItems() []streamItem {
+	items := make([]streamItem, 10000)
+	for i := range items {
+		items[i] = streamItem{ID: i, Name: "item name for the benchmark"}
+	}
+	return items
+}
+
+func BenchmarkWriteJSONStream10k(b *testing.B) {
+	items := benchmarkStreamItems()
+	w := &discardResponseWriter{header: http.Header{}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		WriteJSONStream(w, http.StatusOK, items)
+	}
+}
+
+func BenchmarkWriteJSON10k(b *testing.B) {
+	items := benchmarkStreamItems()
+	w := &discardResponseWriter{header: http.Header{}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		WriteJSON(w, http.StatusOK, items)
+	}
+}
+
+func TestFlushingJSONArrayWriter(t *testing.T) {
+	w := httptest.NewRecorder()
+	writer := FlushingJSONArrayWriter(w)
+	if !w.Flushed || w.Body.String() != "[" {
+		t.Fatalf("after construction: flushed %v, body %q, want the [ flushed", w.Flushed, w.Body.String())
+	}
+
+	items := []streamItem{{1, "a"}, {2, "b"}, {3, "c"}}
+	for i, item := range items {
+		w.Flushed = false
+		if err := writer.Write(item); err != nil {
+			t.Fatal(err)
+		}
+		if !w.Flushed {
+			t.Errorf("item %d was not flushed", i)
+		}
+	}
+	w.Flushed = false
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !w.Flushed {
+		t.Error("Close did not flush")
+	}
+
+	var got []streamItem
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json %q: %v", w.Body.String(), err)
+	}
+	if !reflect.DeepEqual(got, items) {
+		t.Errorf("got %v, want %v", got, items)
+	}
+	if err := writer.Write(streamItem{}); err == nil {
+		t.Error("Write after Close: expected an error")
+	}
+}
+
+func TestFlushingJSONArrayWriterEmpty(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := FlushingJSONArrayWriter(w).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if w.Body.String() != "[]" || w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Errorf("body %q, Content-Type %q", w.Body.String(), w.Header().Get("Content-Type"))
+	}
+}
+
+func TestFlushingJSONArrayWriterWithoutFlusher(t *testing.T) {
+	w := &discardResponseWriter{header: http.Header{}}
+	writer := FlushingJSONArrayWriter(w)
+	if err := writer.Write(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}